@@ -1,25 +1,101 @@
 package clients
 
 import (
+	"time"
+
 	"github.com/thomzes/field-service-booking-app/clients/config"
 	clients "github.com/thomzes/field-service-booking-app/clients/user"
+	"github.com/thomzes/field-service-booking-app/common/cache"
 	config2 "github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
 )
 
-type ClientRegistry struct{}
+type ClientRegistry struct {
+	tokenCache cache.ICache
+	keySet     clients.IKeySet
+}
 
 type IClientRegistry interface {
 	GetUser() clients.IUserClient
 }
 
-func NewClientRegistry() IClientRegistry {
-	return &ClientRegistry{}
+type Option func(*ClientRegistry)
+
+func NewClientRegistry(options ...Option) IClientRegistry {
+	registry := &ClientRegistry{}
+	for _, option := range options {
+		option(registry)
+	}
+	return registry
+}
+
+// WithTokenCache caches user-service token lookups in the given backend.
+func WithTokenCache(tokenCache cache.ICache) Option {
+	return func(c *ClientRegistry) {
+		c.tokenCache = tokenCache
+	}
+}
+
+// WithKeySet provides the keys used by the jwt auth strategies.
+func WithKeySet(keySet clients.IKeySet) Option {
+	return func(c *ClientRegistry) {
+		c.keySet = keySet
+	}
 }
 
 func (c *ClientRegistry) GetUser() clients.IUserClient {
-	return clients.NewUserClient(
+	switch config2.Current().Auth.Strategy {
+	case constants.AuthStrategyJWT:
+		return c.jwtUser()
+	case constants.AuthStrategyJWTRemote:
+		return clients.NewFallbackUserClient(c.jwtUser(), c.remoteUser())
+	default:
+		return c.remoteUser()
+	}
+}
+
+func (c *ClientRegistry) remoteUser() clients.IUserClient {
+	userConfig := config2.Current().InternalService.User
+	userClient := clients.NewUserClient(
 		config.NewClientConfig(
-			config.WithBaseURL(config2.Config.InternalService.User.Host),
-			config.WithSignatureKey(config2.Config.InternalService.User.SignatureKey),
+			config.WithBaseURL(userConfig.Host),
+			config.WithSignatureKey(userConfig.SignatureKey),
 		))
+
+	if c.tokenCache == nil || !userConfig.TokenCache.Enabled {
+		return userClient
+	}
+
+	return clients.NewCachedUserClient(
+		userClient,
+		c.tokenCache,
+		time.Duration(userConfig.TokenCache.TTLSeconds)*time.Second,
+		time.Duration(userConfig.TokenCache.NegativeTTLSeconds)*time.Second,
+	)
+}
+
+func (c *ClientRegistry) jwtUser() clients.IUserClient {
+	jwtConfig := config2.Current().Auth.JWT
+	roleClaim := jwtConfig.RoleClaim
+	if roleClaim == "" {
+		roleClaim = "role"
+	}
+	uuidClaim := jwtConfig.UUIDClaim
+	if uuidClaim == "" {
+		uuidClaim = "uuid"
+	}
+
+	keySet := c.keySet
+	if keySet == nil {
+		keySet = clients.MultiKeySet{}
+	}
+
+	return clients.NewJWTUserClient(keySet, clients.JWTOptions{
+		Issuer:     jwtConfig.Issuer,
+		Audience:   jwtConfig.Audience,
+		Algorithms: jwtConfig.Algorithms,
+		Leeway:     time.Duration(jwtConfig.LeewaySeconds) * time.Second,
+		RoleClaim:  roleClaim,
+		UUIDClaim:  uuidClaim,
+	})
 }
@@ -0,0 +1,94 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/thomzes/field-service-booking-app/common/cache"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
+	"github.com/thomzes/field-service-booking-app/common/util"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+// MaxTokenCacheTTL bounds how long a revoked token can keep being accepted.
+const MaxTokenCacheTTL = 5 * time.Minute
+
+const tokenCacheKeyPrefix = "user:token:"
+
+type cachedUser struct {
+	Valid bool      `json:"valid"`
+	User  *UserData `json:"user,omitempty"`
+}
+
+type CachedUserClient struct {
+	client      IUserClient
+	cache       cache.ICache
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+func NewCachedUserClient(client IUserClient, cache cache.ICache, ttl, negativeTTL time.Duration) IUserClient {
+	return &CachedUserClient{
+		client:      client,
+		cache:       cache,
+		ttl:         min(ttl, MaxTokenCacheTTL),
+		negativeTTL: min(negativeTTL, MaxTokenCacheTTL),
+	}
+}
+
+func tokenCacheKey(token string) string {
+	return tokenCacheKeyPrefix + util.GenerateSHA256(token)
+}
+
+func (c *CachedUserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	token, _ := ctx.Value(constants.Token).(string)
+	key := tokenCacheKey(token)
+
+	value, found, err := c.cache.Get(ctx, key)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to get token from cache: %v", err)
+	}
+
+	if found {
+		var cached cachedUser
+		err = json.Unmarshal(value, &cached)
+		if err == nil {
+			if !cached.Valid {
+				metrics.TokenCacheLookups.WithLabelValues("negative_hit").Inc()
+				return nil, errConstant.ErrInvalidToken
+			}
+			metrics.TokenCacheLookups.WithLabelValues("hit").Inc()
+			return cached.User, nil
+		}
+		logger.FromContext(ctx).Errorf("failed to unmarshal cached token: %v", err)
+	}
+
+	metrics.TokenCacheLookups.WithLabelValues("miss").Inc()
+	user, err := c.client.GetUserByToken(ctx)
+	if err != nil {
+		if errors.Is(err, errConstant.ErrInvalidToken) && c.negativeTTL > 0 {
+			c.store(ctx, key, cachedUser{Valid: false}, c.negativeTTL)
+		}
+		return nil, err
+	}
+
+	c.store(ctx, key, cachedUser{Valid: true, User: user}, c.ttl)
+	return user, nil
+}
+
+func (c *CachedUserClient) store(ctx context.Context, key string, value cachedUser, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to marshal token cache: %v", err)
+		return
+	}
+
+	err = c.cache.Set(ctx, key, data, ttl)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to set token cache: %v", err)
+	}
+}
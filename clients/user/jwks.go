@@ -0,0 +1,299 @@
+package clients
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// minJWKSRefetchInterval limits how often an unknown kid or a failing
+	// endpoint can trigger a refetch.
+	minJWKSRefetchInterval     = 30 * time.Second
+	defaultJWKSRefreshInterval = 5 * time.Minute
+	jwksFetchTimeout           = 5 * time.Second
+)
+
+var (
+	ErrKeyNotFound     = errors.New("signing key not found")
+	ErrKeysUnavailable = errors.New("signing keys unavailable")
+)
+
+type IKeySet interface {
+	Keys(context.Context, string) ([]crypto.PublicKey, error)
+}
+
+type StaticKey struct {
+	KeyID string
+	Key   crypto.PublicKey
+}
+
+type StaticKeySet struct {
+	keys []StaticKey
+}
+
+// NewStaticKeySet parses PEM encoded public keys indexed by kid. Several keys can
+// be configured at once so a new key can be rolled out before the old one is dropped.
+func NewStaticKeySet(pemKeys map[string]string) (IKeySet, error) {
+	keys := make([]StaticKey, 0, len(pemKeys))
+	for kid, pemKey := range pemKeys {
+		key, err := parsePublicKeyPEM([]byte(strings.ReplaceAll(pemKey, "\\n", "\n")))
+		if err != nil {
+			return nil, fmt.Errorf("public key %q: %w", kid, err)
+		}
+		keys = append(keys, StaticKey{KeyID: kid, Key: key})
+	}
+
+	return &StaticKeySet{keys: keys}, nil
+}
+
+func parsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return jwt.ParseEdPublicKeyFromPEM(data)
+}
+
+func (s *StaticKeySet) Keys(_ context.Context, kid string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if kid == "" || key.KeyID == kid {
+			keys = append(keys, key.Key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKSKeySet fetches keys from a JWKS endpoint. Keys are refreshed lazily once
+// refreshInterval has passed, or earlier when a token carries an unknown kid,
+// which is how rotated keys get picked up. Concurrent refreshes share one fetch
+// that runs without holding the lock, and the cached keys keep being served
+// when it fails.
+type JWKSKeySet struct {
+	mutex           sync.RWMutex
+	group           singleflight.Group
+	client          *http.Client
+	url             string
+	refreshInterval time.Duration
+	keys            map[string]crypto.PublicKey
+	fetchedAt       time.Time
+	attemptedAt     time.Time
+}
+
+func NewJWKSKeySet(url string, refreshInterval time.Duration) IKeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	return &JWKSKeySet{
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+		url:             url,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+func (j *JWKSKeySet) Keys(_ context.Context, kid string) ([]crypto.PublicKey, error) {
+	j.mutex.RLock()
+	_, known := j.keys[kid]
+	known = known || (kid == "" && len(j.keys) > 0)
+	stale := time.Since(j.fetchedAt) > j.refreshInterval
+	throttled := time.Since(j.attemptedAt) < minJWKSRefetchInterval
+	j.mutex.RUnlock()
+
+	if !throttled {
+		switch {
+		case !known:
+			// the token needs a key we don't have, wait for it
+			_, _, _ = j.group.Do(j.url, j.refresh)
+		case stale:
+			// the cached keys still verify the token, refresh in the background
+			j.group.DoChan(j.url, j.refresh)
+		}
+	}
+
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	if len(j.keys) == 0 {
+		return nil, ErrKeysUnavailable
+	}
+
+	if kid == "" {
+		keys := make([]crypto.PublicKey, 0, len(j.keys))
+		for _, key := range j.keys {
+			keys = append(keys, key)
+		}
+		return keys, nil
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return []crypto.PublicKey{key}, nil
+}
+
+// refresh fetches the key set and swaps it in. It is detached from the
+// request that triggered it, since other requests may be waiting for it too,
+// and keeps the previous keys when the fetch fails.
+func (j *JWKSKeySet) refresh() (any, error) {
+	j.mutex.Lock()
+	j.attemptedAt = time.Now()
+	j.mutex.Unlock()
+
+	keys, err := j.fetch(context.Background())
+	if err != nil {
+		logrus.Errorf("failed to fetch jwks: %v", err)
+		return nil, err
+	}
+
+	j.mutex.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mutex.Unlock()
+
+	return nil, nil
+}
+
+func (j *JWKSKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	resp, err := j.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks response: %s", resp.Status)
+	}
+
+	var keySet jsonWebKeySet
+	err = json.NewDecoder(resp.Body).Decode(&keySet)
+	if err != nil {
+		return nil, fmt.Errorf("jwks response: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(keySet.Keys))
+	for _, item := range keySet.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+
+		key, err := item.publicKey()
+		if err != nil {
+			logrus.Errorf("failed to parse jwk %q: %v", item.Kid, err)
+			continue
+		}
+		keys[item.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+type MultiKeySet []IKeySet
+
+func (m MultiKeySet) Keys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0)
+	for _, keySet := range m {
+		found, err := keySet.Keys(ctx, kid)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, found...)
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	return keys, nil
+}
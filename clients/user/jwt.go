@@ -0,0 +1,131 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+// ErrCannotVerify is returned with ErrInvalidToken when the token can't be
+// checked locally: it is not a JWT, its kid is unknown or the keys could not be
+// fetched.
+var ErrCannotVerify = errors.New("token cannot be verified locally")
+
+type JWTOptions struct {
+	Issuer     string
+	Audience   string
+	Algorithms []string
+	Leeway     time.Duration
+	RoleClaim  string
+	UUIDClaim  string
+}
+
+// JWTUserClient resolves the user from a signed token without calling the user service.
+type JWTUserClient struct {
+	keySet  IKeySet
+	options JWTOptions
+}
+
+func NewJWTUserClient(keySet IKeySet, options JWTOptions) IUserClient {
+	return &JWTUserClient{keySet: keySet, options: options}
+}
+
+func (j *JWTUserClient) parserOptions() []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(j.options.Leeway),
+	}
+	if len(j.options.Algorithms) > 0 {
+		options = append(options, jwt.WithValidMethods(j.options.Algorithms))
+	}
+	if j.options.Issuer != "" {
+		options = append(options, jwt.WithIssuer(j.options.Issuer))
+	}
+	if j.options.Audience != "" {
+		options = append(options, jwt.WithAudience(j.options.Audience))
+	}
+	return options
+}
+
+func (j *JWTUserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	token, _ := ctx.Value(constants.Token).(string)
+
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, ErrCannotVerify)
+	}
+	kid, _ := unverified.Header["kid"].(string)
+
+	keys, err := j.keySet.Keys(ctx, kid)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to get signing key %q: %v", kid, err)
+		return nil, fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, ErrCannotVerify)
+	}
+
+	var claims jwt.MapClaims
+	for _, key := range keys {
+		claims = jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+			return key, nil
+		}, j.parserOptions()...)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, errConstant.ErrInvalidToken
+	}
+
+	return j.userFromClaims(claims)
+}
+
+func (j *JWTUserClient) userFromClaims(claims jwt.MapClaims) (*UserData, error) {
+	role, _ := claims[j.options.RoleClaim].(string)
+	rawUUID, _ := claims[j.options.UUIDClaim].(string)
+	userUUID, err := uuid.Parse(rawUUID)
+	if err != nil || role == "" {
+		return nil, errConstant.ErrInvalidToken
+	}
+
+	user := &UserData{UUID: userUUID, Role: role}
+	user.Name, _ = claims["name"].(string)
+	user.Username, _ = claims["username"].(string)
+	user.Email, _ = claims["email"].(string)
+	user.PhoneNumber, _ = claims["phoneNumber"].(string)
+	if rawVenueID, ok := claims["venueID"].(string); ok {
+		venueID, err := uuid.Parse(rawVenueID)
+		if err == nil {
+			user.VenueID = &venueID
+		}
+	}
+
+	return user, nil
+}
+
+// FallbackUserClient asks the fallback client when the primary cannot verify
+// the token at all. A token the primary rejected, e.g. an expired one or one
+// with a bad signature, is not retried.
+type FallbackUserClient struct {
+	primary  IUserClient
+	fallback IUserClient
+}
+
+func NewFallbackUserClient(primary, fallback IUserClient) IUserClient {
+	return &FallbackUserClient{primary: primary, fallback: fallback}
+}
+
+func (f *FallbackUserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	user, err := f.primary.GetUserByToken(ctx)
+	if !errors.Is(err, ErrCannotVerify) {
+		return user, err
+	}
+
+	return f.fallback.GetUserByToken(ctx)
+}
@@ -0,0 +1,57 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+type stubUserClient struct {
+	user  *UserData
+	err   error
+	calls int
+}
+
+func (s *stubUserClient) GetUserByToken(context.Context) (*UserData, error) {
+	s.calls++
+	return s.user, s.err
+}
+
+func TestFallbackUserClient(t *testing.T) {
+	remoteUser := &UserData{Role: "remote"}
+
+	tests := []struct {
+		name       string
+		primaryErr error
+		fallback   bool
+	}{
+		{name: "verified locally"},
+		{name: "expired or badly signed token", primaryErr: errConstant.ErrInvalidToken},
+		{name: "unknown kid", primaryErr: fmt.Errorf("%w: %w", errConstant.ErrInvalidToken, ErrCannotVerify), fallback: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary := &stubUserClient{user: &UserData{Role: "local"}, err: test.primaryErr}
+			if test.primaryErr != nil {
+				primary.user = nil
+			}
+			fallback := &stubUserClient{user: remoteUser}
+
+			user, err := NewFallbackUserClient(primary, fallback).GetUserByToken(context.Background())
+			if (fallback.calls == 1) != test.fallback {
+				t.Fatalf("fallback called %d times, want fallback = %t", fallback.calls, test.fallback)
+			}
+
+			switch {
+			case test.fallback && user != remoteUser:
+				t.Fatalf("user = %v, want the fallback user", user)
+			case !test.fallback && !errors.Is(err, test.primaryErr):
+				t.Fatalf("error = %v, want %v", err, test.primaryErr)
+			}
+		})
+	}
+}
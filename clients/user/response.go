@@ -10,10 +10,11 @@ type UserResponse struct {
 }
 
 type UserData struct {
-	UUID        uuid.UUID `json:"uuid"`
-	Name        string    `json:"name"`
-	Username    string    `json:"username"`
-	Email       string    `json:"email"`
-	Role        string    `json:"role"`
-	PhoneNumber string    `json:"phoneNumber"`
+	UUID        uuid.UUID  `json:"uuid"`
+	Name        string     `json:"name"`
+	Username    string     `json:"username"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	PhoneNumber string     `json:"phoneNumber"`
+	VenueID     *uuid.UUID `json:"venueID,omitempty"`
 }
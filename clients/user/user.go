@@ -7,9 +7,15 @@ import (
 	"time"
 
 	"github.com/thomzes/field-service-booking-app/clients/config"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
+	"github.com/thomzes/field-service-booking-app/common/tracing"
 	"github.com/thomzes/field-service-booking-app/common/util"
 	config2 "github.com/thomzes/field-service-booking-app/config"
 	"github.com/thomzes/field-service-booking-app/constants"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UserClient struct {
@@ -27,9 +33,12 @@ func NewUserClient(client config.IClientConfig) IUserClient {
 }
 
 func (u *UserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserClient.GetUserByToken", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
 	unixTime := time.Now().Unix()
 	generateAPIKey := fmt.Sprintf("%s:%s:%d",
-		config2.Config.AppName,
+		config2.Current().AppName,
 		u.client.SignatureKey(),
 		unixTime,
 	)
@@ -40,18 +49,39 @@ func (u *UserClient) GetUserByToken(ctx context.Context) (*UserData, error) {
 	request := u.client.Client().Clone().
 		Set(constants.Authorization, bearerToken).
 		Set(constants.XApiKey, apiKey).
-		Set(constants.XServiceName, config2.Config.AppName).
+		Set(constants.XServiceName, config2.Current().AppName).
 		Set(constants.XRequestAt, fmt.Sprintf("%d", unixTime)).
 		Get(fmt.Sprintf("%s/api/v1/auth/user", u.client.BaseURL()))
+	headers := tracing.InjectHeaders(ctx)
+	for key := range headers {
+		request.Set(key, headers.Get(key))
+	}
 
+	startedAt := time.Now()
 	resp, _, errs := request.EndStruct(&response)
 	if len(errs) > 0 {
+		observeUserClient(startedAt, "error")
+		span.RecordError(errs[0])
+		span.SetStatus(codes.Error, errs[0].Error())
 		return nil, errs[0]
 	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		observeUserClient(startedAt, "invalid_token")
+		return nil, errConstant.ErrInvalidToken
+	}
 
 	if resp.StatusCode != http.StatusOK {
+		observeUserClient(startedAt, "error")
+		span.SetStatus(codes.Error, response.Message)
 		return nil, fmt.Errorf("user response: %s", response.Message)
 	}
 
+	observeUserClient(startedAt, "success")
 	return &response.Data, nil
 }
+
+func observeUserClient(startedAt time.Time, result string) {
+	metrics.UserClientDuration.WithLabelValues(result).Observe(time.Since(startedAt).Seconds())
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/thomzes/field-service-booking-app/config"
+)
+
+var configCommand = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the service configuration",
+}
+
+var configCheckCommand = &cobra.Command{
+	Use:   "check",
+	Short: "Validate a config file and print the effective config with secrets masked",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+
+		appConfig, err := config.Load(file)
+		var validationErr *config.ValidationError
+		if err != nil && !errors.As(err, &validationErr) {
+			return err
+		}
+
+		effective, err := json.MarshalIndent(config.Masked(appConfig), "", "    ")
+		if err != nil {
+			return err
+		}
+		cmd.Println(string(effective))
+
+		if validationErr != nil {
+			cmd.SilenceUsage = true
+			return validationErr
+		}
+		cmd.Println("config is valid")
+		return nil
+	},
+}
+
+// initConfig loads .env and installs the configuration. Its errors are about
+// the configuration, not the command line, so the usage is not printed.
+func initConfig(cmd *cobra.Command) error {
+	_ = godotenv.Load()
+	err := config.Init()
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
+
+func init() {
+	configCheckCommand.Flags().String("file", "config.json", "config file to check, FIELD_SERVICE_* overrides are applied")
+	configCommand.AddCommand(configCheckCommand)
+	command.AddCommand(configCommand)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/thomzes/field-service-booking-app/common/events"
+	"github.com/thomzes/field-service-booking-app/config"
+)
+
+func initEvents() events.IPublisher {
+	eventsConfig := config.Current().Events
+	if eventsConfig.Backend != events.BackendWebhook {
+		return events.NewLogPublisher()
+	}
+
+	publisher, err := events.NewWebhookPublisher(events.WebhookOptions{
+		URL:         eventsConfig.Webhook.URL,
+		ServiceName: config.Current().AppName,
+		SigningKey:  eventsConfig.Webhook.SigningKey,
+		Timeout:     time.Duration(eventsConfig.Webhook.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return publisher
+}
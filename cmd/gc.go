@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/migrations"
+	"github.com/thomzes/field-service-booking-app/repositories"
+	storageGCService "github.com/thomzes/field-service-booking-app/services/storagegc"
+)
+
+var gcStorageCommand = &cobra.Command{
+	Use:   "gc-storage",
+	Short: "Delete stored images that no field references",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		grace, _ := cmd.Flags().GetDuration("grace")
+		retention, _ := cmd.Flags().GetDuration("deleted-retention")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		err := initConfig(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := config.InitDatabase()
+		if err != nil {
+			return err
+		}
+		defer closeDatabase(db)
+
+		err = migrations.CheckVersion(db)
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Flags().Changed("deleted-retention") {
+			retention = time.Duration(config.Current().Trash.RetentionDays) * 24 * time.Hour
+		}
+
+		store := initStorage()
+		defer closeStorage(store)
+
+		collector := storageGCService.NewStorageGCService(repositories.NewRepositoryRegistry(db), store, storageGCService.Options{
+			GracePeriod:      grace,
+			DeletedRetention: retention,
+			DryRun:           dryRun,
+		})
+
+		if interval <= 0 {
+			return runStorageGC(cmd.Context(), cmd, collector)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			err = runStorageGC(ctx, cmd, collector)
+			if err != nil {
+				logrus.Errorf("storage gc failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+func runStorageGC(ctx context.Context, cmd *cobra.Command, collector storageGCService.IStorageGCService) error {
+	summary, err := collector.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	action := "deleted"
+	if summary.DryRun {
+		action = "would delete"
+	}
+	cmd.Printf("scanned: %d, referenced: %d, kept as recent: %d\n", summary.Scanned, summary.Referenced, summary.Recent)
+	cmd.Printf("%s: %d objects (%d bytes), failed: %d\n", action, summary.Deleted, summary.DeletedBytes, summary.Failed)
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d objects could not be deleted", summary.Failed)
+	}
+	return nil
+}
+
+func init() {
+	gcStorageCommand.Flags().Bool("dry-run", false, "report what would be deleted without deleting it")
+	gcStorageCommand.Flags().Duration("grace", 24*time.Hour, "keep objects updated more recently than this")
+	gcStorageCommand.Flags().Duration("deleted-retention", 0, "keep the images of fields deleted more recently than this (default trash.retentionDays)")
+	gcStorageCommand.Flags().Duration("interval", 0, "run again at this interval until interrupted; 0 runs once")
+	command.AddCommand(gcStorageCommand)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thomzes/field-service-booking-app/common/health"
+	"github.com/thomzes/field-service-booking-app/common/storage"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/migrations"
+	"gorm.io/gorm"
+)
+
+const defaultHealthTimeout = 3 * time.Second
+
+func initHealth(db *gorm.DB, store storage.IStorage) health.IChecker {
+	checks := []health.Check{
+		{
+			Name: "database",
+			Run: func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			Name: "migrations",
+			Run: func(ctx context.Context) error {
+				return migrations.CheckReady(db.WithContext(ctx))
+			},
+		},
+	}
+
+	if config.Current().Health.CheckUserService {
+		checks = append(checks, health.Check{
+			Name: "userService",
+			Run:  checkUserService,
+		})
+	}
+
+	if config.Current().Health.CheckStorage {
+		checks = append(checks, health.Check{
+			Name: "storage",
+			Run:  store.Ping,
+		})
+	}
+
+	timeout := secondsOrDefault(config.Current().Health.TimeoutSeconds, defaultHealthTimeout)
+	return health.NewChecker(timeout, checks...)
+}
+
+// checkUserService only checks reachability: any response below 500 counts.
+func checkUserService(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, config.Current().InternalService.User.Host, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("user service responded %s", resp.Status)
+	}
+
+	return nil
+}
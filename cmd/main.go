@@ -1,37 +1,50 @@
 package cmd
 
 import (
-	"encoding/base64"
+	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
 	"time"
 
-	"strings"
-
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth/limiter"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/thomzes/field-service-booking-app/clients"
-	"github.com/thomzes/field-service-booking-app/common/gcs"
+	userClient "github.com/thomzes/field-service-booking-app/clients/user"
+	"github.com/thomzes/field-service-booking-app/common/cache"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
 	"github.com/thomzes/field-service-booking-app/common/response"
+	"github.com/thomzes/field-service-booking-app/common/storage"
+	"github.com/thomzes/field-service-booking-app/common/tracing"
 	"github.com/thomzes/field-service-booking-app/config"
 	"github.com/thomzes/field-service-booking-app/constants"
 	"github.com/thomzes/field-service-booking-app/controllers"
-	"github.com/thomzes/field-service-booking-app/domain/models"
 	"github.com/thomzes/field-service-booking-app/middlewares"
+	"github.com/thomzes/field-service-booking-app/migrations"
 	"github.com/thomzes/field-service-booking-app/repositories"
 	"github.com/thomzes/field-service-booking-app/routes"
 	"github.com/thomzes/field-service-booking-app/services"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 var command = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the server",
 	Run: func(cmd *cobra.Command, args []string) {
-		_ = godotenv.Load()
-		config.Init()
+		err := initConfig(cmd)
+		if err != nil {
+			cmd.PrintErrln("Error:", err)
+			os.Exit(1)
+		}
 		db, err := config.InitDatabase()
 		if err != nil {
 			panic(err)
@@ -43,22 +56,38 @@ var command = &cobra.Command{
 		}
 		time.Local = loc
 
-		err = db.AutoMigrate(
-			&models.Field{},
-			&models.FieldSchedule{},
-			&models.Time{},
-		)
+		err = migrations.CheckVersion(db)
 		if err != nil {
 			panic(err)
 		}
 
-		gcs := initGCS()
-		client := clients.NewClientRegistry()
+		shutdownTracing := initTracing(cmd.Context())
+		err = tracing.RegisterGORM(db)
+		if err != nil {
+			panic(err)
+		}
+
+		store := initStorage()
+		client := clients.NewClientRegistry(
+			clients.WithTokenCache(cache.NewLRUCache(config.Current().InternalService.User.TokenCache.MaxEntries)),
+			clients.WithKeySet(initKeySet()),
+		)
 		repository := repositories.NewRepositoryRegistry(db)
-		service := services.NewServiceRegistry(repository, gcs)
+		service := services.NewServiceRegistry(repository, store, initEvents())
 		controller := controllers.NewControllerRegistry(service)
+		checker := initHealth(db, store)
 
-		router := gin.Default()
+		err = metrics.RegisterGORM(db, config.Current().Database.Name)
+		if err != nil {
+			panic(err)
+		}
+		prometheus.MustRegister(metrics.NewSlotCollector(db))
+
+		router := gin.New()
+		router.Use(otelgin.Middleware(config.Current().AppName, otelgin.WithFilter(isTraced)))
+		router.Use(middlewares.RequestID())
+		router.Use(middlewares.AccessLog(untracedPaths...))
+		router.Use(metrics.HTTPMiddleware())
 		router.Use(middlewares.HandlePanic())
 		router.NoRoute(func(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, response.Response{
@@ -72,58 +101,120 @@ var command = &cobra.Command{
 				Message: "Welcome to field service",
 			})
 		})
+		router.GET("/metrics", metrics.Handler())
+		router.GET("/healthz", checker.Liveness)
+		router.GET("/readyz", checker.Readiness)
 		// handle CORS
-		router.Use(func(ctx *gin.Context) {
-			ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-			ctx.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT")
-			ctx.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-sevice-name, x-api-key, x-request-at")
-			ctx.Next()
-		})
+		router.Use(middlewares.CORS())
 
 		// handle rate limiter
 		lmt := tollbooth.NewLimiter(
-			config.Config.RateLimiterMaxRequest,
+			config.Current().RateLimiterMaxRequest,
 			&limiter.ExpirableOptions{
-				DefaultExpirationTTL: time.Duration(config.Config.RateLimiterTimeSecond) * time.Second,
+				DefaultExpirationTTL: time.Duration(config.Current().RateLimiterTimeSecond) * time.Second,
 			})
 		router.Use(middlewares.RateLimiter(lmt))
 
+		if localStore, ok := store.(storage.ILocalStorage); ok {
+			handler := gin.WrapH(http.StripPrefix(storage.LocalRoutePrefix, localStore.Handler()))
+			router.GET(storage.LocalRoutePrefix+"/*key", handler)
+			router.HEAD(storage.LocalRoutePrefix+"/*key", handler)
+			router.PUT(storage.LocalRoutePrefix+"/*key", handler)
+		}
+
 		group := router.Group("api/v1")
 		route := routes.NewRouteRegistry(controller, group, client)
 		route.Serve()
 
-		port := fmt.Sprintf(":%d", config.Config.Port)
-		router.Run(port)
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		workers := &workerGroup{}
+		workers.Go(ctx, "config-watcher", func(ctx context.Context) {
+			config.Watch(ctx, consulWatchInterval(), func(_, next *config.AppConfig) {
+				applyLiveConfig(next, lmt)
+			})
+		})
+
+		err = runServer(ctx, router, checker.SetShuttingDown)
+		stop()
+		workers.Wait(defaultShutdownTimeout)
+		closeStorage(store)
+		closeDatabase(db)
+		closeTracing(shutdownTracing)
+		if err != nil {
+			panic(err)
+		}
+		logrus.Info("server stopped")
 	},
 }
 
+// Run executes the root command. Cobra has already printed the error, so
+// failures only set the exit code.
 func Run() {
 	err := command.Execute()
 	if err != nil {
-		panic(err)
+		os.Exit(1)
+	}
+}
+
+func consulWatchInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("CONSUL_WATCH_INTERVAL_SECONDS"))
+	if err != nil {
+		return 0
 	}
+	return time.Duration(seconds) * time.Second
+}
+
+// applyLiveConfig pushes reloaded values into subsystems that copy them at
+// startup. Everything else reads config.Current() per request. Raised or lowered
+// limits apply to clients whose token bucket is created after the reload.
+func applyLiveConfig(next *config.AppConfig, lmt *limiter.Limiter) {
+	logger.SetLevel(next.Log.Level)
+	lmt.SetMax(next.RateLimiterMaxRequest)
+	lmt.SetTokenBucketExpirationTTL(time.Duration(next.RateLimiterTimeSecond) * time.Second)
 }
 
-func initGCS() gcs.IGCSClient {
-	decode, err := base64.StdEncoding.DecodeString(config.Config.GCSPrivateKey)
+func initTracing(ctx context.Context) func(context.Context) error {
+	logrus.AddHook(tracing.LogHook{})
+
+	tracingConfig := config.Current().Tracing
+	shutdown, err := tracing.Init(ctx, tracing.Options{
+		ServiceName: config.Current().AppName,
+		Environment: config.Current().AppEnv,
+		Exporter:    tracingConfig.Exporter,
+		Endpoint:    tracingConfig.Endpoint,
+		Insecure:    tracingConfig.Insecure,
+		SampleRatio: tracingConfig.SampleRatio,
+	})
 	if err != nil {
 		panic(err)
 	}
+	return shutdown
+}
+
+// untracedPaths are probes and scrapes, kept out of traces and access logs.
+var untracedPaths = []string{"/metrics", "/healthz", "/readyz"}
+
+func isTraced(request *http.Request) bool {
+	return !slices.Contains(untracedPaths, request.URL.Path)
+}
+
+func initKeySet() userClient.IKeySet {
+	jwtConfig := config.Current().Auth.JWT
+	keySet := userClient.MultiKeySet{}
+
+	if len(jwtConfig.PublicKeys) > 0 {
+		staticKeySet, err := userClient.NewStaticKeySet(jwtConfig.PublicKeys)
+		if err != nil {
+			panic(err)
+		}
+		keySet = append(keySet, staticKeySet)
+	}
 
-	stringPrivateKey := strings.ReplaceAll(string(decode), "\\n", "\n")
-	gcsServiceAccount := gcs.ServiceAccountKeyJSON{
-		Type:                    config.Config.GCSType,
-		ProjectID:               config.Config.GCSProjectID,
-		PrivateKeyID:            config.Config.GCSPrivateKeyID,
-		PrivateKey:              stringPrivateKey,
-		ClientEmail:             config.Config.GCSClientEmail,
-		ClientID:                config.Config.GCSClientID,
-		AuthURI:                 config.Config.GCSAuthURI,
-		TokenURI:                config.Config.GCSTokenURI,
-		AuthProviderX509CertURL: config.Config.GCSAuthProviderX508CertURL,
-		ClientX509CertURL:       config.Config.GCSClientX509CertURL,
-		UniverseDomain:          config.Config.GCSUniverseDomain,
+	if jwtConfig.JWKSURL != "" {
+		refreshInterval := time.Duration(jwtConfig.JWKSRefreshIntervalSeconds) * time.Second
+		keySet = append(keySet, userClient.NewJWKSKeySet(jwtConfig.JWKSURL, refreshInterval))
 	}
-	gcsClient := gcs.NewGCSClient(gcsServiceAccount, config.Config.GCSBucketName)
-	return gcsClient
+
+	return keySet
 }
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/migrations"
+)
+
+var migrateCommand = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the database schema",
+}
+
+var migrateUpCommand = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigration(cmd, func(m *migrate.Migrate) error {
+			return m.Up()
+		})
+	},
+}
+
+var migrateDownCommand = &cobra.Command{
+	Use:   "down [steps]",
+	Short: "Roll back the given number of migrations (default 1)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+		if len(args) == 1 {
+			parsed, err := strconv.Atoi(args[0])
+			if err != nil || parsed < 1 {
+				return errors.New("steps must be a positive number")
+			}
+			steps = parsed
+		}
+
+		return runMigration(cmd, func(m *migrate.Migrate) error {
+			return m.Steps(-steps)
+		})
+	},
+}
+
+var migrateToCommand = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Migrate up or down to the given version",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		return runMigration(cmd, func(m *migrate.Migrate) error {
+			return m.Migrate(uint(version))
+		})
+	},
+}
+
+var migrateForceCommand = &cobra.Command{
+	Use:   "force <version>",
+	Short: "Mark the given version as applied and clear the dirty flag",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+
+		return runMigration(cmd, func(m *migrate.Migrate) error {
+			return m.Force(version)
+		})
+	},
+}
+
+var migrateStatusCommand = &cobra.Command{
+	Use:   "status",
+	Short: "Show the applied and expected schema versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := initConfig(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := config.InitDatabase()
+		if err != nil {
+			return err
+		}
+
+		current, dirty, err := migrations.CurrentVersion(db)
+		if err != nil {
+			return err
+		}
+
+		expected, err := migrations.ExpectedVersion()
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("current version: %d\n", current)
+		cmd.Printf("expected version: %d\n", expected)
+		cmd.Printf("dirty: %t\n", dirty)
+		return nil
+	},
+}
+
+func init() {
+	migrateCommand.AddCommand(
+		migrateUpCommand,
+		migrateDownCommand,
+		migrateToCommand,
+		migrateForceCommand,
+		migrateStatusCommand,
+	)
+	command.AddCommand(migrateCommand)
+}
+
+func runMigration(cmd *cobra.Command, run func(*migrate.Migrate) error) error {
+	err := initConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	db, err := config.InitDatabase()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		sourceErr, dbErr := m.Close()
+		if sourceErr != nil || dbErr != nil {
+			logrus.Errorf("failed to close migrator: %v %v", sourceErr, dbErr)
+		}
+	}()
+
+	err = run(m)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return err
+	}
+	logrus.Infof("schema is at version %d (dirty=%t)", version, dirty)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/migrations"
+	"github.com/thomzes/field-service-booking-app/seeders"
+)
+
+var seedCommand = &cobra.Command{
+	Use:   "seed",
+	Short: "Load fixtures into fields, times and schedules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		profile, _ := cmd.Flags().GetString("profile")
+		if (file == "") == (profile == "") {
+			return errors.New("exactly one of --file or --profile is required")
+		}
+
+		var (
+			fixture *seeders.Fixture
+			err     error
+		)
+		if file != "" {
+			fixture, err = seeders.LoadFile(file)
+		} else {
+			fixture, err = seeders.LoadProfile(profile)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = initConfig(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := config.InitDatabase()
+		if err != nil {
+			return err
+		}
+
+		loc, err := time.LoadLocation("Asia/Jakarta")
+		if err != nil {
+			return err
+		}
+		time.Local = loc
+
+		err = migrations.CheckVersion(db)
+		if err != nil {
+			return err
+		}
+
+		summary, err := seeders.Seed(cmd.Context(), db, fixture)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("times created: %d\n", summary.TimesCreated)
+		cmd.Printf("fields created: %d, updated: %d\n", summary.FieldsCreated, summary.FieldsUpdated)
+		cmd.Printf("schedules created: %d\n", summary.SchedulesCreated)
+		return nil
+	},
+}
+
+func init() {
+	seedCommand.Flags().String("file", "", "path to a YAML or JSON fixture file")
+	seedCommand.Flags().String("profile", "", "built-in fixture profile, e.g. demo")
+	command.AddCommand(seedCommand)
+}
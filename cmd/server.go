@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/config"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReadTimeout       = 15 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultWriteTimeout      = 60 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+// workerGroup tracks background goroutines started with the serve context so
+// shutdown can wait for them once the context is cancelled.
+type workerGroup struct {
+	wg sync.WaitGroup
+}
+
+func (w *workerGroup) Go(ctx context.Context, name string, run func(context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(ctx)
+		logrus.Infof("worker %s stopped", name)
+	}()
+}
+
+func (w *workerGroup) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logrus.Warn("timed out waiting for background workers")
+	}
+}
+
+func secondsOrDefault(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// runServer serves handler until ctx is cancelled. It then calls beforeShutdown,
+// keeps serving for the shutdown delay so load balancers can notice the pod is
+// not ready, stops accepting new connections and waits for in-flight requests
+// up to the shutdown timeout.
+func runServer(ctx context.Context, handler http.Handler, beforeShutdown func()) error {
+	serverConfig := config.Current().Server
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", config.Current().Port),
+		Handler:           handler,
+		ReadTimeout:       secondsOrDefault(serverConfig.ReadTimeoutSeconds, defaultReadTimeout),
+		ReadHeaderTimeout: secondsOrDefault(serverConfig.ReadHeaderTimeoutSeconds, defaultReadHeaderTimeout),
+		WriteTimeout:      secondsOrDefault(serverConfig.WriteTimeoutSeconds, defaultWriteTimeout),
+		IdleTimeout:       secondsOrDefault(serverConfig.IdleTimeoutSeconds, defaultIdleTimeout),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logrus.Infof("listening on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	beforeShutdown()
+	shutdownDelay := time.Duration(serverConfig.ShutdownDelaySeconds) * time.Second
+	if shutdownDelay > 0 {
+		logrus.Infof("shutting down in %s", shutdownDelay)
+		time.Sleep(shutdownDelay)
+	}
+
+	logrus.Info("shutting down, draining in-flight requests")
+	shutdownTimeout := secondsOrDefault(serverConfig.ShutdownTimeoutSeconds, defaultShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	err = <-serverErr
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func closeDatabase(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		logrus.Errorf("failed to get database: %v", err)
+		return
+	}
+
+	err = sqlDB.Close()
+	if err != nil {
+		logrus.Errorf("failed to close database: %v", err)
+	}
+}
+
+// closeTracing flushes buffered spans before the process exits.
+func closeTracing(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+
+	err := shutdown(ctx)
+	if err != nil {
+		logrus.Errorf("failed to shutdown tracing: %v", err)
+	}
+}
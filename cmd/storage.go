@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/storage"
+	"github.com/thomzes/field-service-booking-app/config"
+)
+
+func initStorage() storage.IStorage {
+	storageConfig := config.Current().Storage
+	switch storageConfig.Backend {
+	case storage.BackendS3:
+		store, err := storage.NewS3Storage(storage.S3Options{
+			Endpoint:        storageConfig.S3.Endpoint,
+			Region:          storageConfig.S3.Region,
+			Bucket:          storageConfig.S3.Bucket,
+			AccessKeyID:     storageConfig.S3.AccessKeyID,
+			SecretAccessKey: storageConfig.S3.SecretAccessKey,
+			UseSSL:          storageConfig.S3.UseSSL,
+			PublicURL:       storageConfig.S3.PublicURL,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return store
+	case storage.BackendLocal:
+		store, err := storage.NewLocalStorage(storage.LocalOptions{
+			Directory:  storageConfig.Local.Directory,
+			BaseURL:    storageConfig.Local.BaseURL,
+			SigningKey: storageConfig.Local.SigningKey,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return store
+	default:
+		return initGCS()
+	}
+}
+
+func initGCS() storage.IStorage {
+	decode, err := base64.StdEncoding.DecodeString(config.Current().GCSPrivateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	stringPrivateKey := strings.ReplaceAll(string(decode), "\\n", "\n")
+	gcsServiceAccount := storage.ServiceAccountKeyJSON{
+		Type:                    config.Current().GCSType,
+		ProjectID:               config.Current().GCSProjectID,
+		PrivateKeyID:            config.Current().GCSPrivateKeyID,
+		PrivateKey:              stringPrivateKey,
+		ClientEmail:             config.Current().GCSClientEmail,
+		ClientID:                config.Current().GCSClientID,
+		AuthURI:                 config.Current().GCSAuthURI,
+		TokenURI:                config.Current().GCSTokenURI,
+		AuthProviderX509CertURL: config.Current().GCSAuthProviderX508CertURL,
+		ClientX509CertURL:       config.Current().GCSClientX509CertURL,
+		UniverseDomain:          config.Current().GCSUniverseDomain,
+	}
+	// the client refreshes its token with this context, so it must outlive the command
+	store, err := storage.NewGCSStorage(context.Background(), gcsServiceAccount, config.Current().GCSBucketName)
+	if err != nil {
+		panic(err)
+	}
+	return store
+}
+
+func closeStorage(store storage.IStorage) {
+	err := store.Close()
+	if err != nil {
+		logrus.Errorf("failed to close storage: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// ICache is the backend used for short-lived lookups. The in-memory LRUCache is
+// the default; a shared store (e.g. Redis) can be plugged in by implementing it.
+type ICache interface {
+	Get(context.Context, string) ([]byte, bool, error)
+	Set(context.Context, string, []byte, time.Duration) error
+	// SetNX stores the value only when the key is missing or expired and
+	// reports whether it did, in one atomic step.
+	SetNX(context.Context, string, []byte, time.Duration) (bool, error)
+	Delete(context.Context, string) error
+}
+
+type entry struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+}
+
+type LRUCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+func NewLRUCache(maxEntries int) ICache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (l *LRUCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	item := element.Value.(*entry)
+	if time.Now().After(item.expiredAt) {
+		l.removeElement(element)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(element)
+	return item.value, true, nil
+}
+
+func (l *LRUCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	expiredAt := time.Now().Add(ttl)
+	if element, ok := l.items[key]; ok {
+		item := element.Value.(*entry)
+		item.value = value
+		item.expiredAt = expiredAt
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&entry{key: key, value: value, expiredAt: expiredAt})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.removeElement(l.order.Back())
+	}
+
+	return nil
+}
+
+func (l *LRUCache) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		if time.Now().Before(element.Value.(*entry).expiredAt) {
+			return false, nil
+		}
+		l.removeElement(element)
+	}
+
+	l.items[key] = l.order.PushFront(&entry{key: key, value: value, expiredAt: time.Now().Add(ttl)})
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.removeElement(l.order.Back())
+	}
+
+	return true, nil
+}
+
+func (l *LRUCache) Delete(_ context.Context, key string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		l.removeElement(element)
+	}
+
+	return nil
+}
+
+func (l *LRUCache) removeElement(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*entry).key)
+}
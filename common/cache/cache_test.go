@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUCacheGet(t *testing.T) {
+	// steps set ("+key") or read ("?key") keys in order before key is looked up.
+	tests := []struct {
+		name       string
+		maxEntries int
+		ttl        time.Duration
+		steps      []string
+		key        string
+		found      bool
+	}{
+		{name: "stored key", maxEntries: 2, ttl: time.Minute, steps: []string{"+a"}, key: "a", found: true},
+		{name: "missing key", maxEntries: 2, ttl: time.Minute, steps: []string{"+a"}, key: "b"},
+		{name: "expired key", maxEntries: 2, ttl: -time.Second, steps: []string{"+a"}, key: "a"},
+		{name: "least recently used is evicted", maxEntries: 2, ttl: time.Minute, steps: []string{"+a", "+b", "+c"}, key: "a"},
+		{name: "newest survives eviction", maxEntries: 2, ttl: time.Minute, steps: []string{"+a", "+b", "+c"}, key: "c", found: true},
+		{name: "read keeps key from eviction", maxEntries: 2, ttl: time.Minute, steps: []string{"+a", "+b", "?a", "+c"}, key: "a", found: true},
+		{name: "unread key is evicted instead", maxEntries: 2, ttl: time.Minute, steps: []string{"+a", "+b", "?a", "+c"}, key: "b"},
+		{name: "unbounded cache keeps every key", maxEntries: 0, ttl: time.Minute, steps: []string{"+a", "+b", "+c"}, key: "a", found: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewLRUCache(test.maxEntries)
+			for _, step := range test.steps {
+				key := step[1:]
+				if step[0] == '+' {
+					_ = cache.Set(ctx, key, []byte(key), test.ttl)
+					continue
+				}
+				_, _, _ = cache.Get(ctx, key)
+			}
+
+			value, found, err := cache.Get(ctx, test.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if found != test.found {
+				t.Fatalf("Get(%q) found = %t, want %t", test.key, found, test.found)
+			}
+			if found && string(value) != test.key {
+				t.Fatalf("Get(%q) = %q, want %q", test.key, value, test.key)
+			}
+		})
+	}
+}
+
+func TestLRUCacheSetNX(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing time.Duration
+		stored   bool
+	}{
+		{name: "missing key is stored", stored: true},
+		{name: "live key is kept", existing: time.Minute},
+		{name: "expired key is replaced", existing: -time.Second, stored: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewLRUCache(10)
+			if test.existing != 0 {
+				_ = cache.Set(ctx, "nonce", []byte("old"), test.existing)
+			}
+
+			stored, err := cache.SetNX(ctx, "nonce", []byte("new"), time.Minute)
+			if err != nil {
+				t.Fatalf("SetNX() error = %v", err)
+			}
+			if stored != test.stored {
+				t.Fatalf("SetNX() = %t, want %t", stored, test.stored)
+			}
+
+			want := "old"
+			if test.stored {
+				want = "new"
+			}
+			value, _, _ := cache.Get(ctx, "nonce")
+			if string(value) != want {
+				t.Fatalf("Get() = %q, want %q", value, want)
+			}
+		})
+	}
+}
+
+func TestLRUCacheDelete(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(10)
+	_ = cache.Set(ctx, "a", []byte("a"), time.Minute)
+
+	err := cache.Delete(ctx, "a")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, found, _ := cache.Get(ctx, "a"); found {
+		t.Fatal("Get() found a deleted key")
+	}
+}
@@ -1,12 +1,13 @@
 package error
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/logger"
 )
 
 type ValidationResponse struct {
@@ -38,12 +39,12 @@ func ErrValidationResponse(err error) (validationResponse []ValidationResponse)
 					if count == 1 {
 						validationResponse = append(validationResponse, ValidationResponse{
 							Field:   err.Field(),
-							Message: fmt.Sprintf("errValidator", err.Field()),
+							Message: fmt.Sprintf(errValidator, err.Field()),
 						})
 					} else {
 						validationResponse = append(validationResponse, ValidationResponse{
 							Field:   err.Field(),
-							Message: fmt.Sprintf("errValidator", err.Field(), err.Param()),
+							Message: fmt.Sprintf(errValidator, err.Field(), err.Param()),
 						})
 					}
 				} else {
@@ -60,7 +61,8 @@ func ErrValidationResponse(err error) (validationResponse []ValidationResponse)
 	return validationResponse
 }
 
-func WrapError(err error) error {
-	logrus.Errorf("error: %v", err)
+// WrapError logs err against the request behind ctx and returns it unchanged.
+func WrapError(ctx context.Context, err error) error {
+	logger.FromContext(ctx).Errorf("error: %v", err)
 	return err
 }
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/signature"
+	"github.com/thomzes/field-service-booking-app/common/tracing"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const (
+	BackendLog     = "log"
+	BackendWebhook = "webhook"
+)
+
+const (
+	TypeFieldDeleted     = "field.deleted"
+	TypeBookingCancelled = "field_schedule.booking_cancelled"
+)
+
+// Event is a domain event sent to the other services.
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
+
+// New returns an event of the given type with a fresh ID.
+func New(eventType string, data any) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// IPublisher delivers events. Publish is called after the change is committed,
+// so a failure can only be logged and retried by the caller.
+type IPublisher interface {
+	Publish(context.Context, ...Event) error
+}
+
+// LogPublisher writes events to the log. It is the default when no webhook is
+// configured.
+type LogPublisher struct{}
+
+func NewLogPublisher() IPublisher {
+	return &LogPublisher{}
+}
+
+func (l *LogPublisher) Publish(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"event_id":   event.ID,
+			"event_type": event.Type,
+			"data":       event.Data,
+		}).Info("event published")
+	}
+	return nil
+}
+
+type WebhookOptions struct {
+	URL         string
+	ServiceName string
+	SigningKey  string
+	Timeout     time.Duration
+}
+
+// WebhookPublisher POSTs each event as JSON, signed like the requests this
+// service accepts from its callers.
+type WebhookPublisher struct {
+	options WebhookOptions
+	client  *http.Client
+	path    string
+}
+
+func NewWebhookPublisher(options WebhookOptions) (IPublisher, error) {
+	parsed, err := url.ParseRequestURI(options.URL)
+	if err != nil {
+		return nil, fmt.Errorf("events webhook url: %w", err)
+	}
+
+	return &WebhookPublisher{
+		options: options,
+		client:  &http.Client{Timeout: options.Timeout},
+		path:    parsed.Path,
+	}, nil
+}
+
+func (w *WebhookPublisher) Publish(ctx context.Context, events ...Event) error {
+	for _, event := range events {
+		err := w.send(ctx, event)
+		if err != nil {
+			return fmt.Errorf("publish %s %s: %w", event.Type, event.ID, err)
+		}
+	}
+	return nil
+}
+
+func (w *WebhookPublisher) send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	requestAt := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := event.ID.String()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(constants.XServiceName, w.options.ServiceName)
+	request.Header.Set(constants.XRequestAt, requestAt)
+	request.Header.Set(constants.XRequestNonce, nonce)
+	request.Header.Set(constants.XApiKey, signature.Sign(w.options.SigningKey, w.options.ServiceName,
+		http.MethodPost, w.path, requestAt, nonce, signature.HashBody(body)))
+	headers := tracing.InjectHeaders(ctx)
+	for key := range headers {
+		request.Header.Set(key, headers.Get(key))
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thomzes/field-service-booking-app/common/response"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Check struct {
+	Name string
+	Run  func(context.Context) error
+}
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Checker struct {
+	checks       []Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+type IChecker interface {
+	Liveness(*gin.Context)
+	Readiness(*gin.Context)
+	SetShuttingDown()
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) IChecker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// SetShuttingDown makes readiness fail so the load balancer stops sending
+// traffic while in-flight requests are drained.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, response.Response{
+		Status:  constants.Success,
+		Message: "alive",
+	})
+}
+
+func (c *Checker) Readiness(ctx *gin.Context) {
+	if c.shuttingDown.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, response.Response{
+			Status:  constants.Error,
+			Message: "shutting down",
+		})
+		return
+	}
+
+	results := c.run(ctx.Request.Context())
+
+	code := http.StatusOK
+	status := constants.Success
+	message := "ready"
+	for _, result := range results {
+		if result.Status != StatusUp {
+			code = http.StatusServiceUnavailable
+			status = constants.Error
+			message = "not ready"
+			break
+		}
+	}
+
+	ctx.JSON(code, response.Response{
+		Status:  status,
+		Message: message,
+		Data:    results,
+	})
+}
+
+func (c *Checker) run(ctx context.Context) map[string]CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]CheckResult, len(c.checks))
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			startedAt := time.Now()
+			err := check.Run(ctx)
+			result := CheckResult{
+				Status:    StatusUp,
+				LatencyMs: time.Since(startedAt).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mutex.Lock()
+			results[check.Name] = result
+			mutex.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	return results
+}
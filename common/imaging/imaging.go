@@ -0,0 +1,206 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	SizeThumbnail = "thumbnail"
+	SizeCard      = "card"
+	SizeFull      = "full"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// maxPixels rejects decompression bombs before the image is decoded.
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+// allowedContentTypes are sniffed from the content, never taken from the
+// client supplied header or filename.
+var allowedContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+type Size struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultSizes are generated for every field image. Images are never upscaled.
+var DefaultSizes = []Size{
+	{Name: SizeThumbnail, MaxWidth: 320, MaxHeight: 320},
+	{Name: SizeCard, MaxWidth: 800, MaxHeight: 800},
+	{Name: SizeFull, MaxWidth: 1920, MaxHeight: 1920},
+}
+
+type Variant struct {
+	Size        string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+func (v Variant) Extension() string {
+	if v.Format == FormatJPEG {
+		return "jpg"
+	}
+	return v.Format
+}
+
+type Result struct {
+	// Hash is the sha256 of the uploaded bytes, used to address the variants.
+	Hash     string
+	Width    int
+	Height   int
+	BlurHash string
+	Variants []Variant
+}
+
+// DetectContentType sniffs data and returns ErrUnsupportedImage unless it is
+// an image type the pipeline can decode.
+func DetectContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(allowedContentTypes, contentType) {
+		return "", ErrUnsupportedImage
+	}
+	return contentType, nil
+}
+
+// Process decodes an uploaded image, applies its EXIF orientation and
+// re-encodes it in every size as JPEG (PNG when it has transparency) and
+// WebP. Re-encoding drops all metadata, EXIF and GPS included. The WebP
+// encoder is pure Go and lossless, so for photos it favours fidelity over size.
+func Process(data []byte, sizes []Size) (*Result, error) {
+	_, err := DetectContentType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if imageConfig.Width*imageConfig.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	decoded = applyOrientation(decoded, jpegOrientation(data))
+
+	hash := sha256.Sum256(data)
+	bounds := decoded.Bounds()
+	result := &Result{
+		Hash:   hex.EncodeToString(hash[:]),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}
+
+	result.BlurHash, err = blurhash.Encode(4, 3, resize(decoded, 32, 32))
+	if err != nil {
+		return nil, fmt.Errorf("blurhash: %w", err)
+	}
+
+	format := FormatJPEG
+	if !isOpaque(decoded) {
+		format = FormatPNG
+	}
+
+	for _, size := range sizes {
+		resized := resize(decoded, size.MaxWidth, size.MaxHeight)
+		for _, variantFormat := range []string{format, FormatWebP} {
+			variant, err := encode(resized, size.Name, variantFormat)
+			if err != nil {
+				return nil, err
+			}
+			result.Variants = append(result.Variants, *variant)
+		}
+	}
+
+	return result, nil
+}
+
+func encode(img image.Image, size, format string) (*Variant, error) {
+	buffer := new(bytes.Buffer)
+	var (
+		contentType string
+		err         error
+	)
+	switch format {
+	case FormatJPEG:
+		contentType = "image/jpeg"
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		contentType = "image/png"
+		err = png.Encode(buffer, img)
+	case FormatWebP:
+		contentType = "image/webp"
+		err = nativewebp.Encode(buffer, img, nil)
+	default:
+		return nil, fmt.Errorf("unknown image format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode %s %s: %w", size, format, err)
+	}
+
+	bounds := img.Bounds()
+	return &Variant{
+		Size:        size,
+		Format:      format,
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Data:        buffer.Bytes(),
+	}, nil
+}
+
+// resize scales img to fit within maxWidth x maxHeight keeping its aspect
+// ratio. Smaller images are only copied.
+func resize(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height), 1)
+	targetWidth := max(1, int(float64(width)*scale+0.5))
+	targetHeight := max(1, int(float64(height)*scale+0.5))
+
+	resized := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+	return resized
+}
+
+func isOpaque(img image.Image) bool {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok {
+		return opaque.Opaque()
+	}
+	return false
+}
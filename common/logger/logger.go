@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const redacted = "[REDACTED]"
+
+// redactedHeaders never reach the logs in clear text.
+var redactedHeaders = []string{
+	constants.Authorization,
+	constants.XApiKey,
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Init configures the standard logrus logger. Unknown levels fall back to info.
+func Init(level, format string) {
+	logrus.SetOutput(os.Stdout)
+	if format == FormatText {
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	} else {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	}
+	SetLevel(level)
+}
+
+func SetLevel(level string) {
+	parsedLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		parsedLevel = logrus.InfoLevel
+	}
+	logrus.SetLevel(parsedLevel)
+}
+
+// FromContext returns a log entry carrying the request id and, through the
+// tracing hook, the trace id of the request behind ctx.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx == nil {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	entry := logrus.WithContext(ctx)
+	if requestID := RequestID(ctx); requestID != "" {
+		entry = entry.WithField("request_id", requestID)
+	}
+	return entry
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, constants.RequestID, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(constants.RequestID).(string)
+	return requestID
+}
+
+// RedactHeaders flattens headers for logging and masks credentials.
+func RedactHeaders(headers http.Header) map[string]string {
+	result := make(map[string]string, len(headers))
+	for key := range headers {
+		result[key] = headers.Get(key)
+	}
+	for _, key := range redactedHeaders {
+		if _, ok := result[key]; ok {
+			result[key] = redacted
+		}
+	}
+	return result
+}
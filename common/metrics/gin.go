@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const unmatchedRoute = "unmatched"
+
+func HTTPMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startedAt := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		method := ctx.Request.Method
+		status := strconv.Itoa(ctx.Writer.Status())
+		HTTPRequests.WithLabelValues(method, route, status).Inc()
+		HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(startedAt).Seconds())
+	}
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/constants"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// RegisterGORM records query durations through GORM callbacks and exports the
+// connection pool stats of the underlying sql.DB.
+func RegisterGORM(db *gorm.DB, dbName string) error {
+	callback := db.Callback()
+	err := errors.Join(
+		callback.Create().Before("gorm:create").Register("metrics:before_create", startTimer),
+		callback.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", startTimer),
+		callback.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", startTimer),
+		callback.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", startTimer),
+		callback.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw")),
+	)
+	if err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+}
+
+func startTimer(tx *gorm.DB) {
+	tx.InstanceSet(startedAtKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		startedAt, _ := value.(time.Time)
+
+		table := tx.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		result := "success"
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			result = "error"
+		}
+		DBQueryDuration.WithLabelValues(operation, table, result).Observe(time.Since(startedAt).Seconds())
+	}
+}
+
+var slotsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "field_schedule_upcoming_slots"),
+	"Upcoming field schedule slots by status.",
+	[]string{"status"}, nil,
+)
+
+// SlotCollector reports upcoming slots per status at scrape time.
+type SlotCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewSlotCollector(db *gorm.DB) prometheus.Collector {
+	return &SlotCollector{db: db, timeout: 2 * time.Second}
+}
+
+func (s *SlotCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- slotsDesc
+}
+
+func (s *SlotCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var rows []struct {
+		Status constants.FieldScheduleStatus
+		Total  int64
+	}
+	err := s.db.WithContext(ctx).
+		Table("field_schedules").
+		Select("status, count(*) AS total").
+		Where("date >= current_date AND deleted_at IS NULL").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		logrus.Errorf("failed to collect slot metrics: %v", err)
+		return
+	}
+
+	for _, row := range rows {
+		ch <- prometheus.MustNewConstMetric(slotsDesc, prometheus.GaugeValue, float64(row.Total), string(row.Status.GetStatusString()))
+	}
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "field_service"
+
+// Labels only carry bounded values: gin route templates (never raw paths),
+// status codes, table names and fixed result names.
+var (
+	TokenCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_token_cache_lookups_total",
+		Help:      "User token cache lookups by result (hit, negative_hit, miss).",
+	}, []string{"result"})
+
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "result"})
+
+	UserClientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "user_client_request_duration_seconds",
+		Help:      "User service call latency by result (success, invalid_token, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	RateLimitRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+
+	SlotsGenerated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "field_schedule_slots_generated_total",
+		Help:      "Field schedule slots created.",
+	})
+
+	SlotStatusChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "field_schedule_slot_status_changes_total",
+		Help:      "Field schedule slots booked, or released by a forced field delete.",
+	}, []string{"status"})
+
+	BookingConflicts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "field_schedule_conflicts_total",
+		Help:      "Attempts to create or book a slot that is already taken, by operation.",
+	}, []string{"operation"})
+)
+
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
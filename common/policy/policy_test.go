@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	clients "github.com/thomzes/field-service-booking-app/clients/user"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+func TestAuthorize(t *testing.T) {
+	venue := uuid.New()
+	otherVenue := uuid.New()
+
+	config.Set(&config.AppConfig{
+		Authorization: config.Authorization{
+			RolePermissions: map[string][]string{
+				constants.Admin:        {string(constants.FieldWrite), string(constants.ScheduleWrite)},
+				constants.VenueManager: {string(constants.FieldRead), string(constants.ScheduleWrite) + constants.VenueScope},
+				"mixed":                {string(constants.ScheduleWrite) + constants.VenueScope, string(constants.ScheduleWrite)},
+			},
+		},
+	})
+	t.Cleanup(func() { config.Set(&config.AppConfig{}) })
+
+	tests := []struct {
+		name       string
+		user       *clients.UserData
+		permission constants.Permission
+		resource   Resource
+		err        error
+	}{
+		{name: "no user", permission: constants.FieldRead, err: errConstant.ErrUnauthorize},
+		{name: "global grant", user: &clients.UserData{Role: constants.Admin}, permission: constants.FieldWrite, resource: Resource{VenueID: &venue}},
+		{name: "global grant without venue", user: &clients.UserData{Role: constants.Admin}, permission: constants.ScheduleWrite},
+		{name: "permission not granted", user: &clients.UserData{Role: constants.Admin}, permission: constants.TimeWrite, err: errConstant.ErrForbidden},
+		{name: "unknown role", user: &clients.UserData{Role: "guest"}, permission: constants.FieldRead, err: errConstant.ErrForbidden},
+		{name: "venue grant on own venue", user: &clients.UserData{Role: constants.VenueManager, VenueID: &venue}, permission: constants.ScheduleWrite, resource: Resource{VenueID: &venue}},
+		{name: "venue grant on other venue", user: &clients.UserData{Role: constants.VenueManager, VenueID: &venue}, permission: constants.ScheduleWrite, resource: Resource{VenueID: &otherVenue}, err: errConstant.ErrForbidden},
+		{name: "venue grant on resource without venue", user: &clients.UserData{Role: constants.VenueManager, VenueID: &venue}, permission: constants.ScheduleWrite, err: errConstant.ErrForbidden},
+		{name: "venue grant for user without venue", user: &clients.UserData{Role: constants.VenueManager}, permission: constants.ScheduleWrite, resource: Resource{VenueID: &venue}, err: errConstant.ErrForbidden},
+		{name: "global grant wins over venue grant", user: &clients.UserData{Role: "mixed", VenueID: &venue}, permission: constants.ScheduleWrite, resource: Resource{VenueID: &otherVenue}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.user != nil {
+				ctx = context.WithValue(ctx, constants.User, test.user)
+			}
+
+			err := Authorize(ctx, test.permission, test.resource)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("Authorize() error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestGrantForDefaultPolicy(t *testing.T) {
+	config.Set(&config.AppConfig{})
+
+	tests := []struct {
+		role       string
+		permission constants.Permission
+		grant      Grant
+	}{
+		{role: constants.Admin, permission: constants.FieldWrite, grant: GrantAll},
+		{role: constants.Customer, permission: constants.ScheduleRead, grant: GrantAll},
+		{role: constants.Customer, permission: constants.FieldWrite, grant: GrantNone},
+		{role: constants.VenueManager, permission: constants.ScheduleWrite, grant: GrantVenue},
+		{role: constants.VenueManager, permission: constants.FieldWrite, grant: GrantNone},
+		{role: constants.Support, permission: constants.TimeRead, grant: GrantAll},
+	}
+
+	for _, test := range tests {
+		t.Run(test.role+" "+string(test.permission), func(t *testing.T) {
+			grant := GrantFor(test.role, test.permission)
+			if grant != test.grant {
+				t.Fatalf("GrantFor(%q, %q) = %d, want %d", test.role, test.permission, grant, test.grant)
+			}
+		})
+	}
+}
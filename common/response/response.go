@@ -1,9 +1,11 @@
 package response
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/thomzes/field-service-booking-app/common/tracing"
 	"github.com/thomzes/field-service-booking-app/constants"
 	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
 )
@@ -13,6 +15,7 @@ type Response struct {
 	Message any         `json:"message"`
 	Data    interface{} `json:"data"`
 	Token   *string     `json:"token,omitempty"`
+	TraceID string      `json:"traceID,omitempty"`
 }
 
 type ParamHTTPResp struct {
@@ -35,6 +38,12 @@ func HttpResponse(param ParamHTTPResp) {
 		return
 	}
 
+	// policy denials are reported as such whatever code the controller chose
+	code := param.Code
+	if errors.Is(param.Err, errConstant.ErrForbidden) {
+		code = http.StatusForbidden
+	}
+
 	message := errConstant.ErrInternalServerError.Error()
 	if param.Message != nil {
 		message = *param.Message
@@ -44,10 +53,11 @@ func HttpResponse(param ParamHTTPResp) {
 		}
 	}
 
-	param.Gin.JSON(param.Code, Response{
+	param.Gin.JSON(code, Response{
 		Status:  constants.Error,
 		Message: message,
 		Data:    param.Data,
+		TraceID: tracing.TraceID(param.Gin.Request.Context()),
 	})
 
 	return
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+func TestHttpResponseStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		code   int
+		err    error
+		status int
+	}{
+		{name: "success", code: http.StatusOK, status: http.StatusOK},
+		{name: "bad request", code: http.StatusBadRequest, err: errConstant.ErrSQLError, status: http.StatusBadRequest},
+		{name: "forbidden", code: http.StatusBadRequest, err: errConstant.ErrForbidden, status: http.StatusForbidden},
+		{name: "wrapped forbidden", code: http.StatusBadRequest, err: fmt.Errorf("venue: %w", errConstant.ErrForbidden), status: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			HttpResponse(ParamHTTPResp{Code: test.code, Err: test.err, Gin: ctx})
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, test.status)
+			}
+		})
+	}
+}
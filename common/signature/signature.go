@@ -0,0 +1,44 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HashBody returns the hex encoded sha256 of the request body.
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the canonical request:
+// service, method, path, request time, nonce and body hash joined by newlines.
+func Sign(key, serviceName, method, path, requestAt, nonce, bodyHash string) string {
+	canonical := strings.Join([]string{
+		serviceName,
+		strings.ToUpper(method),
+		path,
+		requestAt,
+		nonce,
+		bodyHash,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignLegacy returns sha256(service:key:requestAt), the scheme used before HMAC signatures.
+func SignLegacy(key, serviceName, requestAt string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", serviceName, key, requestAt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal compares two signatures in constant time.
+func Equal(expected, actual string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
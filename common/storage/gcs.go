@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+type ServiceAccountKeyJSON struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"project_id"`
+	PrivateKeyID            string `json:"private_key_id"`
+	PrivateKey              string `json:"private_key"`
+	ClientEmail             string `json:"client_email"`
+	ClientID                string `json:"client_id"`
+	AuthURI                 string `json:"auth_uri"`
+	TokenURI                string `json:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url"`
+	UniverseDomain          string `json:"universe_domain"`
+}
+
+// GCSStorage shares one client, which is safe for concurrent use, between
+// all calls.
+type GCSStorage struct {
+	client     *gcs.Client
+	BucketName string
+}
+
+func NewGCSStorage(ctx context.Context, serviceAccountKeyJSON ServiceAccountKeyJSON, bucketName string) (IStorage, error) {
+	credentials, err := json.Marshal(serviceAccountKeyJSON)
+	if err != nil {
+		return nil, fmt.Errorf("encode service account key json: %w", err)
+	}
+
+	client, err := gcs.NewClient(ctx, option.WithCredentialsJSON(credentials))
+	if err != nil {
+		return nil, fmt.Errorf("create gcs client: %w", err)
+	}
+
+	return &GCSStorage{
+		client:     client,
+		BucketName: bucketName,
+	}, nil
+}
+
+func (g *GCSStorage) Upload(ctx context.Context, key string, data []byte, contentType string) (url string, err error) {
+	timeoutInSeconds := 60
+
+	ctx, span := startSpan(ctx, "GCSStorage.Upload", BackendGCS, key)
+	defer func() { endSpan(span, err) }()
+
+	uploadCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutInSeconds)*time.Second)
+	defer cancel()
+
+	writer := g.client.Bucket(g.BucketName).Object(key).NewWriter(uploadCtx)
+	writer.ChunkSize = 0
+	writer.ContentType = contentTypeOrDefault(contentType)
+
+	_, err = io.Copy(writer, bytes.NewReader(data))
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to copy: %v", err)
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to close: %v", err)
+		return "", err
+	}
+
+	return fmt.Sprintf("https://storage.googleapis.com/%s/%s", g.BucketName, key), nil
+}
+
+func (g *GCSStorage) Key(url string) (string, bool) {
+	return keyFromURL(fmt.Sprintf("https://storage.googleapis.com/%s", g.BucketName), url)
+}
+
+func (g *GCSStorage) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := startSpan(ctx, "GCSStorage.Delete", BackendGCS, key)
+	defer func() { endSpan(span, err) }()
+
+	err = g.client.Bucket(g.BucketName).Object(key).Delete(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (g *GCSStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	attrs, err := g.client.Bucket(g.BucketName).Object(key).Attrs(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{
+		Key:         attrs.Name,
+		Size:        attrs.Size,
+		ContentType: attrs.ContentType,
+		UpdatedAt:   attrs.Updated,
+	}, nil
+}
+
+func (g *GCSStorage) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	objects := g.client.Bucket(g.BucketName).Objects(ctx, &gcs.Query{Prefix: prefix})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(Object{
+			Key:         attrs.Name,
+			Size:        attrs.Size,
+			ContentType: attrs.ContentType,
+			UpdatedAt:   attrs.Updated,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (g *GCSStorage) Download(ctx context.Context, key string, maxSize int64) (data []byte, err error) {
+	ctx, span := startSpan(ctx, "GCSStorage.Download", BackendGCS, key)
+	defer func() { endSpan(span, err) }()
+
+	reader, err := g.client.Bucket(g.BucketName).Object(key).NewReader(ctx)
+	if errors.Is(err, gcs.ErrObjectNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readLimited(reader, maxSize)
+}
+
+func (g *GCSStorage) SignedURL(ctx context.Context, key, method string, expiry time.Duration) (string, error) {
+	if !isSignableMethod(method) {
+		return "", errUnsupportedMethod
+	}
+
+	return g.client.Bucket(g.BucketName).SignedURL(key, &gcs.SignedURLOptions{
+		Scheme:  gcs.SigningSchemeV4,
+		Method:  method,
+		Expires: time.Now().Add(expiry),
+	})
+}
+
+// SignedUpload returns a V4 POST policy, so GCS itself rejects other content
+// types and larger files.
+func (g *GCSStorage) SignedUpload(_ context.Context, key string, policy UploadPolicy) (*SignedUpload, error) {
+	postPolicy, err := g.client.Bucket(g.BucketName).GenerateSignedPostPolicyV4(key, &gcs.PostPolicyV4Options{
+		Expires: time.Now().Add(policy.Expiry),
+		Fields:  &gcs.PolicyV4Fields{ContentType: policy.ContentType},
+		Conditions: []gcs.PostPolicyV4Condition{
+			gcs.ConditionContentLengthRange(1, uint64(policy.MaxSize)),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedUpload{URL: postPolicy.URL, Method: http.MethodPost, Fields: postPolicy.Fields}, nil
+}
+
+// Ping checks that the bucket exists and the credentials can read it.
+func (g *GCSStorage) Ping(ctx context.Context) error {
+	_, err := g.client.Bucket(g.BucketName).Attrs(ctx)
+	return err
+}
+
+func (g *GCSStorage) Close() error {
+	return g.client.Close()
+}
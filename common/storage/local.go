@@ -0,0 +1,366 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalRoutePrefix is where the service serves the local backend's files.
+const LocalRoutePrefix = "/static"
+
+const maxLocalUploadSize = 32 << 20
+
+var errInvalidKey = errors.New("invalid object key")
+
+type LocalOptions struct {
+	Directory string
+	// BaseURL is the public URL of LocalRoutePrefix, e.g. http://localhost:8002/static.
+	BaseURL    string
+	SigningKey string
+}
+
+// LocalStorage keeps objects on the filesystem for development and tests.
+// Files are public through Handler; writes need a URL from SignedURL.
+type LocalStorage struct {
+	directory  string
+	baseURL    string
+	signingKey []byte
+}
+
+type ILocalStorage interface {
+	IStorage
+	Handler() http.Handler
+}
+
+func NewLocalStorage(options LocalOptions) (ILocalStorage, error) {
+	directory, err := filepath.Abs(options.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(directory, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		directory:  directory,
+		baseURL:    strings.TrimSuffix(options.BaseURL, "/"),
+		signingKey: []byte(options.SigningKey),
+	}, nil
+}
+
+// resolve maps key to a path inside the storage directory.
+func (l *LocalStorage) resolve(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(l.directory, filepath.FromSlash(cleaned)), nil
+}
+
+func (l *LocalStorage) Upload(ctx context.Context, key string, data []byte, _ string) (url string, err error) {
+	_, span := startSpan(ctx, "LocalStorage.Upload", BackendLocal, key)
+	defer func() { endSpan(span, err) }()
+
+	err = l.write(key, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", l.baseURL, key), nil
+}
+
+// write stores the object through a temporary file so readers never see a
+// partially written object.
+func (l *LocalStorage) write(key string, reader io.Reader) error {
+	filename, err := l.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filename)
+}
+
+func (l *LocalStorage) Key(url string) (string, bool) {
+	return keyFromURL(l.baseURL, url)
+}
+
+func (l *LocalStorage) Delete(_ context.Context, key string) error {
+	filename, err := l.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *LocalStorage) Stat(_ context.Context, key string) (*Object, error) {
+	filename, err := l.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType, err = sniffContentType(filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentTypeOrDefault(contentType),
+		UpdatedAt:   info.ModTime(),
+	}, nil
+}
+
+// sniffContentType detects the content type of files stored without an
+// extension, such as direct uploads.
+func sniffContentType(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return http.DetectContentType(header[:n]), nil
+}
+
+func (l *LocalStorage) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	err := filepath.WalkDir(l.directory, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(l.directory, filename)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		return fn(Object{
+			Key:         key,
+			Size:        info.Size(),
+			ContentType: contentTypeOrDefault(mime.TypeByExtension(path.Ext(key))),
+			UpdatedAt:   info.ModTime(),
+		})
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *LocalStorage) Download(_ context.Context, key string, maxSize int64) ([]byte, error) {
+	filename, err := l.resolve(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readLimited(file, maxSize)
+}
+
+func (l *LocalStorage) SignedURL(_ context.Context, key, method string, expiry time.Duration) (string, error) {
+	if !isSignableMethod(method) {
+		return "", errUnsupportedMethod
+	}
+	return l.signedURL(key, method, expiry, url.Values{})
+}
+
+// SignedUpload returns a PUT URL that carries the content type and maximum
+// size, both covered by the signature and enforced by Handler.
+func (l *LocalStorage) SignedUpload(_ context.Context, key string, policy UploadPolicy) (*SignedUpload, error) {
+	query := url.Values{}
+	query.Set("contentType", policy.ContentType)
+	query.Set("maxSize", strconv.FormatInt(policy.MaxSize, 10))
+
+	signedURL, err := l.signedURL(key, http.MethodPut, policy.Expiry, query)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedUpload{
+		URL:     signedURL,
+		Method:  http.MethodPut,
+		Headers: map[string]string{"Content-Type": policy.ContentType},
+	}, nil
+}
+
+func (l *LocalStorage) signedURL(key, method string, expiry time.Duration, query url.Values) (string, error) {
+	if _, err := l.resolve(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(method, key, expires, query.Get("contentType"), query.Get("maxSize")))
+	return fmt.Sprintf("%s/%s?%s", l.baseURL, key, query.Encode()), nil
+}
+
+func (l *LocalStorage) Ping(context.Context) error {
+	info, err := os.Stat(l.directory)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", l.directory)
+	}
+	return nil
+}
+
+func (l *LocalStorage) Close() error {
+	return nil
+}
+
+func (l *LocalStorage) sign(method, key, expires, contentType, maxSize string) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(strings.Join([]string{method, key, expires, contentType, maxSize}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (l *LocalStorage) verify(method, key string, query url.Values) bool {
+	expires := query.Get("expires")
+	unixTime, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unixTime {
+		return false
+	}
+	expected := l.sign(method, key, expires, query.Get("contentType"), query.Get("maxSize"))
+	return hmac.Equal([]byte(expected), []byte(query.Get("signature")))
+}
+
+// uploadLimit returns how many bytes a verified PUT may write and whether its
+// content type is the one it was signed for.
+func uploadLimit(r *http.Request) (int64, bool) {
+	query := r.URL.Query()
+	if contentType := query.Get("contentType"); contentType != "" && r.Header.Get("Content-Type") != contentType {
+		return 0, false
+	}
+
+	maxSize, err := strconv.ParseInt(query.Get("maxSize"), 10, 64)
+	if err != nil || maxSize <= 0 || maxSize > maxLocalUploadSize {
+		return maxLocalUploadSize, true
+	}
+	return maxSize, true
+}
+
+// Handler serves GET for every object and PUT for signed URLs. It expects the
+// request path to be the object key, so mount it behind http.StripPrefix.
+func (l *LocalStorage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		filename, err := l.resolve(key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			info, err := os.Stat(filename)
+			if err != nil || info.IsDir() {
+				http.NotFound(w, r)
+				return
+			}
+			http.ServeFile(w, r, filename)
+		case http.MethodPut:
+			if !l.verify(http.MethodPut, key, r.URL.Query()) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			maxSize, ok := uploadLimit(r)
+			if !ok {
+				http.Error(w, "content type does not match the signed url", http.StatusForbidden)
+				return
+			}
+
+			err = l.write(key, http.MaxBytesReader(w, r.Body, maxSize))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStorageSignedUpload(t *testing.T) {
+	local, err := NewLocalStorage(LocalOptions{Directory: t.TempDir(), BaseURL: "http://localhost/static", SigningKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		query       func(string) string
+		status      int
+	}{
+		{name: "signed content type and size", contentType: "image/png", body: "12345678", status: http.StatusOK},
+		{name: "other content type", contentType: "text/html", body: "12345678", status: http.StatusForbidden},
+		{name: "larger than signed", contentType: "image/png", body: "123456789", status: http.StatusBadRequest},
+		{
+			name:        "raised size limit",
+			contentType: "image/png",
+			body:        "123456789",
+			query:       func(query string) string { return strings.Replace(query, "maxSize=8", "maxSize=9", 1) },
+			status:      http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upload, err := local.SignedUpload(context.Background(), "uploads/field/image", UploadPolicy{
+				ContentType: "image/png",
+				MaxSize:     8,
+				Expiry:      time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("SignedUpload() error = %v", err)
+			}
+
+			target := strings.TrimPrefix(upload.URL, "http://localhost/static")
+			if test.query != nil {
+				target = test.query(target)
+			}
+			request := httptest.NewRequest(upload.Method, target, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+
+			recorder := httptest.NewRecorder()
+			local.Handler().ServeHTTP(recorder, request)
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body.String())
+			}
+		})
+	}
+}
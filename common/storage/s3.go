@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	// PublicURL is the base URL objects are served from, e.g. a CDN. It
+	// defaults to the path-style bucket URL on the endpoint.
+	PublicURL string
+}
+
+// S3Storage works with AWS S3 and S3-compatible stores such as MinIO.
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func NewS3Storage(options S3Options) (IStorage, error) {
+	client, err := minio.New(options.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(options.AccessKeyID, options.SecretAccessKey, ""),
+		Secure: options.UseSSL,
+		Region: options.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicURL := options.PublicURL
+	if publicURL == "" {
+		scheme := "http"
+		if options.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, options.Endpoint, options.Bucket)
+	}
+
+	return &S3Storage{
+		client:    client,
+		bucket:    options.Bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+func (s *S3Storage) Upload(ctx context.Context, key string, data []byte, contentType string) (url string, err error) {
+	ctx, span := startSpan(ctx, "S3Storage.Upload", BackendS3, key)
+	defer func() { endSpan(span, err) }()
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentTypeOrDefault(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s", s.publicURL, key), nil
+}
+
+func (s *S3Storage) Key(url string) (string, bool) {
+	return keyFromURL(s.publicURL, url)
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) (err error) {
+	ctx, span := startSpan(ctx, "S3Storage.Delete", BackendS3, key)
+	defer func() { endSpan(span, err) }()
+
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	return &Object{
+		Key:         info.Key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		UpdatedAt:   info.LastModified,
+	}, nil
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return info.Err
+		}
+
+		err := fn(Object{
+			Key:         info.Key,
+			Size:        info.Size,
+			ContentType: info.ContentType,
+			UpdatedAt:   info.LastModified,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *S3Storage) Download(ctx context.Context, key string, maxSize int64) (data []byte, err error) {
+	ctx, span := startSpan(ctx, "S3Storage.Download", BackendS3, key)
+	defer func() { endSpan(span, err) }()
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	data, err = readLimited(object, maxSize)
+	if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	return data, err
+}
+
+func (s *S3Storage) SignedURL(ctx context.Context, key, method string, expiry time.Duration) (string, error) {
+	if !isSignableMethod(method) {
+		return "", errUnsupportedMethod
+	}
+
+	signedURL, err := s.client.Presign(ctx, method, s.bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return signedURL.String(), nil
+}
+
+// SignedUpload returns a presigned POST policy, so the bucket itself rejects
+// other content types and larger files.
+func (s *S3Storage) SignedUpload(ctx context.Context, key string, policy UploadPolicy) (*SignedUpload, error) {
+	postPolicy := minio.NewPostPolicy()
+	err := errors.Join(
+		postPolicy.SetBucket(s.bucket),
+		postPolicy.SetKey(key),
+		postPolicy.SetExpires(time.Now().UTC().Add(policy.Expiry)),
+		postPolicy.SetContentType(policy.ContentType),
+		postPolicy.SetContentLengthRange(1, policy.MaxSize),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	signedURL, fields, err := s.client.PresignedPostPolicy(ctx, postPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedUpload{URL: signedURL.String(), Method: http.MethodPost, Fields: fields}, nil
+}
+
+func (s *S3Storage) Close() error {
+	return nil
+}
+
+func (s *S3Storage) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucket)
+	}
+	return nil
+}
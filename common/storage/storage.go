@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thomzes/field-service-booking-app/common/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	BackendGCS   = "gcs"
+	BackendS3    = "s3"
+	BackendLocal = "local"
+)
+
+const defaultContentType = "application/octet-stream"
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrObjectTooLarge = errors.New("object is too large")
+)
+
+// Object describes a stored object.
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	UpdatedAt   time.Time
+}
+
+// UploadPolicy limits what a signed upload accepts.
+type UploadPolicy struct {
+	ContentType string
+	MaxSize     int64
+	Expiry      time.Duration
+}
+
+// SignedUpload tells a client how to upload an object. For POST the Fields go
+// into a multipart form before the file, for PUT the Headers must be sent as
+// they are, since they are part of the signature.
+type SignedUpload struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+	Fields  map[string]string
+}
+
+// IStorage is implemented by every object storage backend. Keys are slash
+// separated paths such as "images/field.png".
+type IStorage interface {
+	// Upload stores data under key and returns its public URL.
+	Upload(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Key returns the key behind a URL returned by Upload, or false when the
+	// URL does not point into this storage.
+	Key(url string) (string, bool)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// Stat returns ErrObjectNotFound when key does not exist.
+	Stat(ctx context.Context, key string) (*Object, error)
+	// List calls fn for every object whose key starts with prefix, stopping at
+	// the first error fn returns.
+	List(ctx context.Context, prefix string, fn func(Object) error) error
+	// Download reads key, failing with ErrObjectTooLarge past maxSize bytes.
+	Download(ctx context.Context, key string, maxSize int64) ([]byte, error)
+	// SignedURL returns a URL that allows method (GET or PUT) on key until it expires.
+	SignedURL(ctx context.Context, key, method string, expiry time.Duration) (string, error)
+	// SignedUpload lets a client upload key directly, only with the content
+	// type and up to the size of policy.
+	SignedUpload(ctx context.Context, key string, policy UploadPolicy) (*SignedUpload, error)
+	Ping(ctx context.Context) error
+	// Close releases the client of the backend.
+	Close() error
+}
+
+func contentTypeOrDefault(contentType string) string {
+	if contentType == "" {
+		return defaultContentType
+	}
+	return contentType
+}
+
+func readLimited(reader io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrObjectTooLarge
+	}
+	return data, nil
+}
+
+func isSignableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut
+}
+
+// keyFromURL trims the public base URL of a backend off url.
+func keyFromURL(baseURL, url string) (string, bool) {
+	key, found := strings.CutPrefix(url, baseURL+"/")
+	if !found || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+var errUnsupportedMethod = errors.New("signed urls only support GET and PUT")
+
+func startSpan(ctx context.Context, name, backend, key string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(
+		attribute.String("storage.backend", backend),
+		attribute.String("storage.key", key),
+	))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
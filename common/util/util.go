@@ -9,11 +9,13 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper/remote"
+	"gorm.io/gorm"
 )
 
 type PaginationParam struct {
@@ -79,6 +81,14 @@ func RupiahFormat(amount *float64) string {
 	return fmt.Sprintf("Rp. %s", stringValue)
 }
 
+// DeletedAt returns when a soft-deleted row was deleted, or nil for a live row.
+func DeletedAt(deletedAt *gorm.DeletedAt) *time.Time {
+	if deletedAt == nil || !deletedAt.Valid {
+		return nil
+	}
+	return &deletedAt.Time
+}
+
 func BindFromJSON(dest any, filename, path string) error {
 	v := viper.New()
 
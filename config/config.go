@@ -1,28 +1,60 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 
-	"github.com/sirupsen/logrus"
 	_ "github.com/spf13/viper/remote"
+	"github.com/thomzes/field-service-booking-app/common/logger"
 	"github.com/thomzes/field-service-booking-app/common/util"
 )
 
-var Config AppConfig
+var current atomic.Pointer[AppConfig]
+
+// Current returns the active configuration. The returned value is shared and
+// must be treated as read-only; reloads swap in a new value instead of
+// mutating it, so callers that need consistency should read it once.
+func Current() *AppConfig {
+	config := current.Load()
+	if config == nil {
+		return &AppConfig{}
+	}
+	return config
+}
+
+// Set installs config as the active configuration as it is, without defaults
+// or validation. Tests use it to run against a fixed configuration.
+func Set(config *AppConfig) {
+	current.Store(config)
+}
 
 type AppConfig struct {
 	Port                       int             `json:"port"`
+	Server                     Server          `json:"server"`
+	Health                     Health          `json:"health"`
 	AppName                    string          `json:"appName"`
 	AppEnv                     string          `json:"appEnv"`
-	SignatureKey               string          `json:"signatureKey"`
+	SignatureKey               string          `json:"signatureKey" secret:"true"`
+	APISignature               APISignature    `json:"apiSignature"`
+	CallerServices             []CallerService `json:"callerServices"`
 	Database                   Database        `json:"database"`
 	RateLimiterMaxRequest      float64         `json:"rateLimiterMaxRequest"`
 	RateLimiterTimeSecond      int             `json:"rateLimiterTimeSecond"`
 	InternalService            InternalService `json:"internalService"`
+	Auth                       Auth            `json:"auth"`
+	Authorization              Authorization   `json:"authorization"`
+	Tracing                    Tracing         `json:"tracing"`
+	Log                        Log             `json:"log"`
+	CORS                       CORS            `json:"cors"`
+	Storage                    Storage         `json:"storage"`
+	Trash                      Trash           `json:"trash"`
+	Events                     Events          `json:"events"`
 	GCSType                    string          `json:"gcsType"`
 	GCSProjectID               string          `json:"gcsProjectID"`
-	GCSPrivateKeyID            string          `json:"gcsPrivateKeyID"`
-	GCSPrivateKey              string          `json:"gcsPrivateKey"`
+	GCSPrivateKeyID            string          `json:"gcsPrivateKeyID" secret:"true"`
+	GCSPrivateKey              string          `json:"gcsPrivateKey" secret:"true"`
 	GCSClientEmail             string          `json:"gcsClientEmail"`
 	GCSClientID                string          `json:"gcsClientID"`
 	GCSAuthURI                 string          `json:"gcsAuthURI"`
@@ -33,12 +65,42 @@ type AppConfig struct {
 	GCSBucketName              string          `json:"gcsBucketName"`
 }
 
+type Server struct {
+	ReadTimeoutSeconds       int `json:"readTimeoutSeconds"`
+	ReadHeaderTimeoutSeconds int `json:"readHeaderTimeoutSeconds"`
+	WriteTimeoutSeconds      int `json:"writeTimeoutSeconds"`
+	IdleTimeoutSeconds       int `json:"idleTimeoutSeconds"`
+	ShutdownTimeoutSeconds   int `json:"shutdownTimeoutSeconds"`
+	ShutdownDelaySeconds     int `json:"shutdownDelaySeconds"`
+}
+
+type Health struct {
+	TimeoutSeconds   int  `json:"timeoutSeconds"`
+	CheckUserService bool `json:"checkUserService"`
+	CheckStorage     bool `json:"checkStorage"`
+}
+
+type APISignature struct {
+	AllowLegacy     bool `json:"allowLegacy"`
+	MaxSkewSeconds  int  `json:"maxSkewSeconds"`
+	NonceTTLSeconds int  `json:"nonceTTLSeconds"`
+	NonceCacheSize  int  `json:"nonceCacheSize"`
+	MaxBodyBytes    int  `json:"maxBodyBytes"`
+}
+
+type CallerService struct {
+	Name          string   `json:"name"`
+	ActiveKey     string   `json:"activeKey" secret:"true"`
+	PreviousKeys  []string `json:"previousKeys" secret:"true"`
+	AllowedRoutes []string `json:"allowedRoutes"`
+}
+
 type Database struct {
 	Host                  string `json:"host"`
 	Port                  int    `json:"port"`
 	Name                  string `json:"name"`
 	Username              string `json:"username"`
-	Password              string `json:"password"`
+	Password              string `json:"password" secret:"true"`
 	MaxOpenConnection     int    `json:"maxOpenConnection"`
 	MaxLifeTimeConnection int    `json:"maxLifeTimeConnection"`
 	MaxIdleConnection     int    `json:"maxIdleConnection"`
@@ -50,18 +112,167 @@ type InternalService struct {
 }
 
 type User struct {
-	Host         string `json:"host"`
-	SignatureKey string `json:"signatureKey"`
+	Host         string     `json:"host"`
+	SignatureKey string     `json:"signatureKey" secret:"true"`
+	TokenCache   TokenCache `json:"tokenCache"`
+}
+
+type TokenCache struct {
+	Enabled            bool `json:"enabled"`
+	MaxEntries         int  `json:"maxEntries"`
+	TTLSeconds         int  `json:"ttlSeconds"`
+	NegativeTTLSeconds int  `json:"negativeTTLSeconds"`
+}
+
+type Auth struct {
+	Strategy string `json:"strategy"`
+	JWT      JWT    `json:"jwt"`
+}
+
+type JWT struct {
+	JWKSURL                    string            `json:"jwksURL"`
+	JWKSRefreshIntervalSeconds int               `json:"jwksRefreshIntervalSeconds"`
+	PublicKeys                 map[string]string `json:"publicKeys"`
+	Issuer                     string            `json:"issuer"`
+	Audience                   string            `json:"audience"`
+	Algorithms                 []string          `json:"algorithms"`
+	LeewaySeconds              int               `json:"leewaySeconds"`
+	RoleClaim                  string            `json:"roleClaim"`
+	UUIDClaim                  string            `json:"uuidClaim"`
+}
+
+// Authorization maps roles to permissions such as "field:write". A permission
+// suffixed with "@venue" only applies to the user's own venue. When empty the
+// default policy is used.
+type Authorization struct {
+	RolePermissions map[string][]string `json:"rolePermissions"`
+}
+
+// Tracing selects the span exporter: "otlp", "stdout" or "none".
+type Tracing struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+// Log sets the minimum level ("debug", "info", "warn", "error") and the
+// output format ("json" or "text").
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// Trash keeps soft-deleted fields and schedules restorable for RetentionDays
+// before they can be purged.
+type Trash struct {
+	RetentionDays int `json:"retentionDays"`
+}
+
+// Events selects where domain events go: "log" or "webhook". The webhook is
+// signed with SigningKey the same way callers sign requests to this service.
+type Events struct {
+	Backend string  `json:"backend"`
+	Webhook Webhook `json:"webhook"`
 }
 
-func Init() {
-	err := util.BindFromJSON(&Config, "config.json", ".")
+type Webhook struct {
+	URL            string `json:"url"`
+	SigningKey     string `json:"signingKey" secret:"true"`
+	TimeoutSeconds int    `json:"timeoutSeconds"`
+}
+
+// Storage selects where uploads go: "gcs" (the gcs* keys), "s3" or "local".
+type Storage struct {
+	Backend string       `json:"backend"`
+	S3      S3Storage    `json:"s3"`
+	Local   LocalStorage `json:"local"`
+}
+
+type S3Storage struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"accessKeyID"`
+	SecretAccessKey string `json:"secretAccessKey" secret:"true"`
+	UseSSL          bool   `json:"useSSL"`
+	PublicURL       string `json:"publicURL"`
+}
+
+type LocalStorage struct {
+	Directory  string `json:"directory"`
+	BaseURL    string `json:"baseURL"`
+	SigningKey string `json:"signingKey" secret:"true"`
+}
+
+// CORS lists the origins allowed to call the API. Empty or "*" allows any.
+type CORS struct {
+	AllowedOrigins []string `json:"allowedOrigins"`
+}
+
+// Init loads, validates and installs the configuration. A ValidationError
+// lists every invalid key.
+func Init() error {
+	config, err := load()
 	if err != nil {
-		logrus.Errorf("failed to bind env from json: %v", err)
-		err = util.BindFromConsul(&Config, os.Getenv("CONSUL_HTTP_URL"), os.Getenv("CONSUL_HTTP_KEY"))
-		if err != nil {
-			panic(err)
-		}
+		return err
 	}
 
+	err = prepare(config)
+	if err != nil {
+		return err
+	}
+
+	current.Store(config)
+	logger.Init(config.Log.Level, config.Log.Format)
+	return nil
+}
+
+// load reads config.json from the working directory, or the Consul KV key from
+// CONSUL_HTTP_URL and CONSUL_HTTP_KEY when there is no such file. Without
+// either the configuration starts empty, so only the defaults and the
+// FIELD_SERVICE_* variables apply.
+func load() (*AppConfig, error) {
+	_, err := os.Stat(configFileName)
+	switch {
+	case err == nil:
+		source = sourceFile
+	case os.Getenv("CONSUL_HTTP_URL") != "":
+		source = sourceConsul
+	default:
+		source = sourceEnv
+	}
+
+	config, err := loadFrom(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w", source, err)
+	}
+	return config, nil
+}
+
+// Load reads and validates the given config file without installing it, with
+// FIELD_SERVICE_* overrides and defaults applied.
+func Load(filename string) (*AppConfig, error) {
+	var config AppConfig
+	err := util.BindFromJSON(&config, filepath.Base(filename), filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, prepare(&config)
+}
+
+func loadFrom(from configSource) (*AppConfig, error) {
+	var config AppConfig
+	var err error
+	switch from {
+	case sourceFile:
+		err = util.BindFromJSON(&config, configFileName, ".")
+	case sourceConsul:
+		err = util.BindFromConsul(&config, os.Getenv("CONSUL_HTTP_URL"), os.Getenv("CONSUL_HTTP_KEY"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
 }
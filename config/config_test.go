@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestInitFromEnvironment(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CONSUL_HTTP_URL", "")
+	t.Cleanup(func() { Set(&AppConfig{}) })
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		valid bool
+	}{
+		{name: "nothing configured"},
+		{
+			name: "required keys from the environment",
+			env: map[string]string{
+				"FIELD_SERVICE_APP_NAME":                   "field-service",
+				"FIELD_SERVICE_SIGNATURE_KEY":              "secret",
+				"FIELD_SERVICE_DATABASE_HOST":              "localhost",
+				"FIELD_SERVICE_DATABASE_NAME":              "field",
+				"FIELD_SERVICE_DATABASE_USERNAME":          "field",
+				"FIELD_SERVICE_INTERNAL_SERVICE_USER_HOST": "http://localhost:8001",
+				"FIELD_SERVICE_STORAGE_BACKEND":            "local",
+				"FIELD_SERVICE_STORAGE_LOCAL_SIGNING_KEY":  "secret",
+			},
+			valid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			err := Init()
+			if (err == nil) != test.valid {
+				t.Fatalf("Init() error = %v, want valid = %t", err, test.valid)
+			}
+			if source != sourceEnv {
+				t.Fatalf("source = %s, want %s", source, sourceEnv)
+			}
+			if test.valid && Current().AppName != os.Getenv("FIELD_SERVICE_APP_NAME") {
+				t.Fatalf("appName = %q, want it from the environment", Current().AppName)
+			}
+		})
+	}
+}
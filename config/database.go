@@ -10,7 +10,7 @@ import (
 )
 
 func InitDatabase() (*gorm.DB, error) {
-	config := Config
+	config := Current()
 
 	encodedPassword := url.QueryEscape(config.Database.Password)
 
@@ -22,7 +22,7 @@ func InitDatabase() (*gorm.DB, error) {
 		config.Database.Name,
 	)
 
-	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(uri), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +35,7 @@ func InitDatabase() (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(config.Database.MaxIdleConnection)
 	sqlDB.SetMaxOpenConns(config.Database.MaxOpenConnection)
 	sqlDB.SetConnMaxLifetime(time.Duration(config.Database.MaxLifeTimeConnection) * time.Second)
-	sqlDB.SetConnMaxIdleTime(time.Duration(config.Database.MaxIdleConnection) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(config.Database.MaxIdleTime) * time.Second)
 
 	return db, nil
 }
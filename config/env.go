@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix prefixes the environment overrides. Every key of config.json can
+// be overridden by its path in upper snake case, e.g. database.maxOpenConnection
+// by FIELD_SERVICE_DATABASE_MAX_OPEN_CONNECTION. Lists of strings are comma
+// separated; maps and lists of objects are JSON.
+const EnvPrefix = "FIELD_SERVICE"
+
+func applyEnvOverrides(config *AppConfig) []string {
+	value := reflect.ValueOf(config).Elem()
+
+	var problems []string
+	walkFields(value.Type(), EnvPrefix, func(env string, index []int) {
+		raw, ok := os.LookupEnv(env)
+		if !ok {
+			return
+		}
+
+		err := setFromString(value.FieldByIndex(index), raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", env, err))
+		}
+	})
+	return problems
+}
+
+// walkFields calls visit for every leaf of a config struct with its
+// environment variable name and field index.
+func walkFields(t reflect.Type, prefix string, visit func(env string, index []int)) {
+	var walk func(t reflect.Type, prefix string, index []int)
+	walk = func(t reflect.Type, prefix string, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+
+			env := prefix + "_" + toSnakeUpper(name)
+			fieldIndex := append(append([]int{}, index...), i)
+			if field.Type.Kind() == reflect.Struct {
+				walk(field.Type, env, fieldIndex)
+				continue
+			}
+			visit(env, fieldIndex)
+		}
+	}
+	walk(t, prefix, nil)
+}
+
+func setFromString(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		field.SetBool(parsed)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String {
+			values := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					values = append(values, item)
+				}
+			}
+			field.Set(reflect.ValueOf(values))
+			return nil
+		}
+		return setFromJSON(field, raw)
+	case reflect.Map:
+		return setFromJSON(field, raw)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func setFromJSON(field reflect.Value, raw string) error {
+	target := reflect.New(field.Type())
+	err := json.Unmarshal([]byte(raw), target.Interface())
+	if err != nil {
+		return fmt.Errorf("invalid json: %v", err)
+	}
+	field.Set(target.Elem())
+	return nil
+}
+
+// toSnakeUpper turns a camelCase json key into UPPER_SNAKE_CASE, keeping
+// acronyms together: nonceTTLSeconds becomes NONCE_TTL_SECONDS.
+func toSnakeUpper(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
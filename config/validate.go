@@ -0,0 +1,272 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const masked = "********"
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (v *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(v.Problems, "\n  - ")
+}
+
+// applyDefaults fills in keys left empty or zero.
+func applyDefaults(config *AppConfig) {
+	setDefault(&config.Port, 8002)
+	setDefault(&config.AppEnv, "development")
+	setDefault(&config.Server.ReadTimeoutSeconds, 15)
+	setDefault(&config.Server.ReadHeaderTimeoutSeconds, 5)
+	setDefault(&config.Server.WriteTimeoutSeconds, 60)
+	setDefault(&config.Server.IdleTimeoutSeconds, 120)
+	setDefault(&config.Server.ShutdownTimeoutSeconds, 30)
+	setDefault(&config.Health.TimeoutSeconds, 3)
+	setDefault(&config.APISignature.MaxSkewSeconds, 300)
+	setDefault(&config.APISignature.NonceTTLSeconds, 600)
+	setDefault(&config.APISignature.NonceCacheSize, 100000)
+	setDefault(&config.APISignature.MaxBodyBytes, 64<<20)
+	setDefault(&config.Database.Port, 5432)
+	setDefault(&config.Database.MaxOpenConnection, 10)
+	setDefault(&config.Database.MaxIdleConnection, 5)
+	setDefault(&config.Database.MaxLifeTimeConnection, 3600)
+	setDefault(&config.Database.MaxIdleTime, 600)
+	setDefault(&config.RateLimiterMaxRequest, 100)
+	setDefault(&config.RateLimiterTimeSecond, 60)
+	setDefault(&config.InternalService.User.TokenCache.MaxEntries, 10000)
+	setDefault(&config.InternalService.User.TokenCache.TTLSeconds, 60)
+	setDefault(&config.Auth.Strategy, constants.AuthStrategyRemote)
+	setDefault(&config.Auth.JWT.RoleClaim, "role")
+	setDefault(&config.Auth.JWT.UUIDClaim, "uuid")
+	setDefault(&config.Tracing.Exporter, "none")
+	setDefault(&config.Tracing.SampleRatio, 1)
+	setDefault(&config.Log.Level, "info")
+	setDefault(&config.Log.Format, logger.FormatJSON)
+	setDefault(&config.Storage.Backend, "gcs")
+	setDefault(&config.Storage.Local.Directory, "storage")
+	setDefault(&config.Storage.Local.BaseURL, fmt.Sprintf("http://localhost:%d/static", config.Port))
+	setDefault(&config.Trash.RetentionDays, 30)
+	setDefault(&config.Events.Backend, "log")
+	setDefault(&config.Events.Webhook.TimeoutSeconds, 5)
+}
+
+func setDefault[T comparable](field *T, value T) {
+	var zero T
+	if *field == zero {
+		*field = value
+	}
+}
+
+// prepare applies FIELD_SERVICE_* overrides and defaults to a freshly loaded
+// config, then validates it.
+func prepare(config *AppConfig) error {
+	envProblems := applyEnvOverrides(config)
+	applyDefaults(config)
+
+	err := validate(config)
+	if len(envProblems) == 0 {
+		return err
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		envProblems = append(envProblems, validationErr.Problems...)
+	}
+	return &ValidationError{Problems: envProblems}
+}
+
+func validate(config *AppConfig) error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	required := map[string]string{
+		"appName":           config.AppName,
+		"database.host":     config.Database.Host,
+		"database.name":     config.Database.Name,
+		"database.username": config.Database.Username,
+	}
+	for _, key := range sortedKeys(required) {
+		if strings.TrimSpace(required[key]) == "" {
+			add("%s is required", key)
+		}
+	}
+
+	if config.SignatureKey == "" && len(config.CallerServices) == 0 {
+		add("signatureKey or callerServices is required")
+	}
+	for i, caller := range config.CallerServices {
+		if caller.Name == "" {
+			add("callerServices[%d].name is required", i)
+		}
+		if caller.ActiveKey == "" {
+			add("callerServices[%d].activeKey is required", i)
+		}
+	}
+
+	if config.APISignature.MaxSkewSeconds < 0 {
+		add("apiSignature.maxSkewSeconds must not be negative")
+	}
+	if config.APISignature.NonceTTLSeconds < 2*config.APISignature.MaxSkewSeconds {
+		add("apiSignature.nonceTTLSeconds must be at least twice apiSignature.maxSkewSeconds (%d), got %d",
+			2*config.APISignature.MaxSkewSeconds, config.APISignature.NonceTTLSeconds)
+	}
+	if config.APISignature.MaxBodyBytes <= 0 {
+		add("apiSignature.maxBodyBytes must be greater than 0")
+	}
+
+	checkRange(add, "port", config.Port, 1, 65535)
+	checkRange(add, "database.port", config.Database.Port, 1, 65535)
+	checkRange(add, "database.maxOpenConnection", config.Database.MaxOpenConnection, 1, 1000)
+	checkRange(add, "database.maxIdleConnection", config.Database.MaxIdleConnection, 1, config.Database.MaxOpenConnection)
+	checkRange(add, "rateLimiterTimeSecond", config.RateLimiterTimeSecond, 1, 86400)
+	if config.RateLimiterMaxRequest <= 0 {
+		add("rateLimiterMaxRequest must be greater than 0")
+	}
+	checkRange(add, "trash.retentionDays", config.Trash.RetentionDays, 1, 3650)
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		add("tracing.sampleRatio must be between 0 and 1")
+	}
+
+	switch config.Auth.Strategy {
+	case constants.AuthStrategyRemote, constants.AuthStrategyJWTRemote:
+		if _, err := url.ParseRequestURI(config.InternalService.User.Host); err != nil {
+			add("internalService.user.host must be a url when auth.strategy is %q", config.Auth.Strategy)
+		}
+	case constants.AuthStrategyJWT:
+	default:
+		add("auth.strategy must be one of %q, %q or %q",
+			constants.AuthStrategyRemote, constants.AuthStrategyJWT, constants.AuthStrategyJWTRemote)
+	}
+	if config.Auth.Strategy != constants.AuthStrategyRemote &&
+		config.Auth.JWT.JWKSURL == "" && len(config.Auth.JWT.PublicKeys) == 0 {
+		add("auth.jwt.jwksURL or auth.jwt.publicKeys is required when auth.strategy is %q", config.Auth.Strategy)
+	}
+
+	switch config.Storage.Backend {
+	case "gcs":
+		if config.GCSBucketName == "" {
+			add("gcsBucketName is required when storage.backend is gcs")
+		}
+		if config.GCSPrivateKey == "" {
+			add("gcsPrivateKey is required when storage.backend is gcs")
+		} else if _, err := base64.StdEncoding.DecodeString(config.GCSPrivateKey); err != nil {
+			add("gcsPrivateKey must be base64 encoded")
+		}
+	case "s3":
+		if config.Storage.S3.Endpoint == "" {
+			add("storage.s3.endpoint is required when storage.backend is s3")
+		}
+		if config.Storage.S3.Bucket == "" {
+			add("storage.s3.bucket is required when storage.backend is s3")
+		}
+	case "local":
+		if config.Storage.Local.SigningKey == "" {
+			add("storage.local.signingKey is required when storage.backend is local")
+		}
+	default:
+		add("storage.backend must be one of gcs, s3 or local")
+	}
+
+	switch config.Events.Backend {
+	case "log":
+	case "webhook":
+		if _, err := url.ParseRequestURI(config.Events.Webhook.URL); err != nil {
+			add("events.webhook.url must be a url when events.backend is webhook")
+		}
+		if config.Events.Webhook.SigningKey == "" {
+			add("events.webhook.signingKey is required when events.backend is webhook")
+		}
+		checkRange(add, "events.webhook.timeoutSeconds", config.Events.Webhook.TimeoutSeconds, 1, 60)
+	default:
+		add("events.backend must be log or webhook")
+	}
+
+	if !slices.Contains([]string{"none", "stdout", "otlp"}, config.Tracing.Exporter) {
+		add("tracing.exporter must be one of none, stdout or otlp")
+	}
+	if _, err := logrus.ParseLevel(config.Log.Level); err != nil {
+		add("log.level %q is not a log level", config.Log.Level)
+	}
+	if config.Log.Format != logger.FormatJSON && config.Log.Format != logger.FormatText {
+		add("log.format must be json or text")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func checkRange(add func(string, ...any), key string, value, min, max int) {
+	if value < min || value > max {
+		add("%s must be between %d and %d, got %d", key, min, max, value)
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Masked returns a copy of config with every field tagged secret:"true"
+// replaced, for printing.
+func Masked(config *AppConfig) *AppConfig {
+	copied := *config
+	copied.CallerServices = slices.Clone(config.CallerServices)
+	for i := range copied.CallerServices {
+		copied.CallerServices[i].PreviousKeys = slices.Clone(copied.CallerServices[i].PreviousKeys)
+	}
+
+	maskSecrets(reflect.ValueOf(&copied).Elem())
+	return &copied
+}
+
+func maskSecrets(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if value.Type().Field(i).Tag.Get("secret") == "true" {
+				maskValue(field)
+				continue
+			}
+			maskSecrets(field)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			maskSecrets(value.Index(i))
+		}
+	}
+}
+
+func maskValue(value reflect.Value) {
+	switch value.Kind() {
+	case reflect.String:
+		if value.String() != "" {
+			value.SetString(masked)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			maskValue(value.Index(i))
+		}
+	}
+}
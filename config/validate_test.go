@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateAPISignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature APISignature
+		problem   string
+	}{
+		{name: "defaults", signature: APISignature{}},
+		{name: "nonce ttl covers the skew window", signature: APISignature{MaxSkewSeconds: 60, NonceTTLSeconds: 120}},
+		{name: "negative skew", signature: APISignature{MaxSkewSeconds: -1, NonceTTLSeconds: 600}, problem: "apiSignature.maxSkewSeconds"},
+		{name: "nonce ttl shorter than the skew window", signature: APISignature{MaxSkewSeconds: 300, NonceTTLSeconds: 599}, problem: "apiSignature.nonceTTLSeconds"},
+		{name: "negative body limit", signature: APISignature{MaxBodyBytes: -1}, problem: "apiSignature.maxBodyBytes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &AppConfig{
+				AppName:         "field-service",
+				SignatureKey:    "secret",
+				APISignature:    test.signature,
+				Database:        Database{Host: "localhost", Name: "field", Username: "field"},
+				InternalService: InternalService{User: User{Host: "http://localhost:8001"}},
+				Storage:         Storage{Backend: "local", Local: LocalStorage{SigningKey: "secret"}},
+			}
+			applyDefaults(config)
+
+			var problems []string
+			var validationErr *ValidationError
+			if errors.As(validate(config), &validationErr) {
+				problems = validationErr.Problems
+			}
+
+			found := false
+			for _, problem := range problems {
+				found = found || strings.HasPrefix(problem, test.problem+" ")
+			}
+			switch {
+			case test.problem == "" && len(problems) > 0:
+				t.Fatalf("validate() problems = %q, want none", problems)
+			case test.problem != "" && !found:
+				t.Fatalf("validate() problems = %q, want one about %s", problems, test.problem)
+			}
+		})
+	}
+}
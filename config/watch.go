@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+const configFileName = "config.json"
+
+const defaultWatchInterval = 60 * time.Second
+
+type configSource int
+
+const (
+	sourceFile configSource = iota
+	sourceConsul
+	// sourceEnv has no file or Consul key behind it: only the defaults and the
+	// FIELD_SERVICE_* variables, which can't change while the service runs.
+	sourceEnv
+)
+
+func (s configSource) String() string {
+	switch s {
+	case sourceFile:
+		return configFileName
+	case sourceConsul:
+		return "consul"
+	}
+	return "environment"
+}
+
+// source is set once by Init and only read afterwards.
+var source configSource
+
+// restartFields are read once at startup. A reload keeps their running values
+// in Current(), so the rest of the service never sees settings that do not
+// match what it was started with, and logs a change as a warning. Each entry
+// copies the field from previous into next and reports whether it differed.
+var restartFields = map[string]func(previous, next *AppConfig) bool{
+	"port":                        func(p, n *AppConfig) bool { return keep(&n.Port, p.Port) },
+	"server":                      func(p, n *AppConfig) bool { return keep(&n.Server, p.Server) },
+	"health":                      func(p, n *AppConfig) bool { return keep(&n.Health, p.Health) },
+	"appName":                     func(p, n *AppConfig) bool { return keep(&n.AppName, p.AppName) },
+	"appEnv":                      func(p, n *AppConfig) bool { return keep(&n.AppEnv, p.AppEnv) },
+	"database":                    func(p, n *AppConfig) bool { return keep(&n.Database, p.Database) },
+	"apiSignature.nonceCacheSize": func(p, n *AppConfig) bool { return keep(&n.APISignature.NonceCacheSize, p.APISignature.NonceCacheSize) },
+	"internalService.user.tokenCache.maxEntries": func(p, n *AppConfig) bool {
+		return keep(&n.InternalService.User.TokenCache.MaxEntries, p.InternalService.User.TokenCache.MaxEntries)
+	},
+	"auth.jwt.jwksURL": func(p, n *AppConfig) bool { return keep(&n.Auth.JWT.JWKSURL, p.Auth.JWT.JWKSURL) },
+	"auth.jwt.jwksRefreshIntervalSeconds": func(p, n *AppConfig) bool {
+		return keep(&n.Auth.JWT.JWKSRefreshIntervalSeconds, p.Auth.JWT.JWKSRefreshIntervalSeconds)
+	},
+	"auth.jwt.publicKeys": func(p, n *AppConfig) bool { return keep(&n.Auth.JWT.PublicKeys, p.Auth.JWT.PublicKeys) },
+	"tracing":             func(p, n *AppConfig) bool { return keep(&n.Tracing, p.Tracing) },
+	"log.format":          func(p, n *AppConfig) bool { return keep(&n.Log.Format, p.Log.Format) },
+	"storage":             func(p, n *AppConfig) bool { return keep(&n.Storage, p.Storage) },
+	"events":              func(p, n *AppConfig) bool { return keep(&n.Events, p.Events) },
+	"gcs": func(p, n *AppConfig) bool {
+		changed := false
+		previous := gcsFields(p)
+		for i, field := range gcsFields(n) {
+			changed = keep(field, *previous[i]) || changed
+		}
+		return changed
+	},
+}
+
+func gcsFields(c *AppConfig) []*string {
+	return []*string{
+		&c.GCSType, &c.GCSProjectID, &c.GCSPrivateKeyID, &c.GCSPrivateKey, &c.GCSClientEmail,
+		&c.GCSClientID, &c.GCSAuthURI, &c.GCSTokenURI, &c.GCSAuthProviderX508CertURL,
+		&c.GCSClientX509CertURL, &c.GCSUniverseDomain, &c.GCSBucketName,
+	}
+}
+
+// keep sets *next to previous and reports whether they differed.
+func keep[T any](next *T, previous T) bool {
+	changed := !reflect.DeepEqual(*next, previous)
+	*next = previous
+	return changed
+}
+
+// keepRestartFields copies the restart fields of previous into next and
+// returns the names of those that changed.
+func keepRestartFields(previous, next *AppConfig) []string {
+	var changed []string
+	for name, field := range restartFields {
+		if field(previous, next) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Watch reloads the configuration from the source Init used until ctx is
+// cancelled: config.json through fsnotify, Consul by polling every interval.
+// A valid change is swapped into Current() and passed to onChange; an
+// invalid one is logged and the running configuration is kept. A
+// configuration taken from the environment alone is not watched.
+func Watch(ctx context.Context, interval time.Duration, onChange func(previous, next *AppConfig)) {
+	if source == sourceEnv {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	if source == sourceFile {
+		err := watchFile(ctx, onChange)
+		if err == nil {
+			return
+		}
+		logrus.Errorf("failed to watch %s, polling instead: %v", configFileName, err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload(onChange)
+		}
+	}
+}
+
+func watchFile(ctx context.Context, onChange func(previous, next *AppConfig)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Watch the directory so editors that replace the file, and mounted
+	// ConfigMaps that swap a symlink, are still picked up.
+	path, err := filepath.Abs(configFileName)
+	if err != nil {
+		return err
+	}
+	err = watcher.Add(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	// Editors emit several events per save; reload once they settle.
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Base(event.Name) == configFileName || event.Has(fsnotify.Create) {
+				debounce.Reset(500 * time.Millisecond)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.Errorf("config watcher error: %v", err)
+		case <-debounce.C:
+			reload(onChange)
+		}
+	}
+}
+
+func reload(onChange func(previous, next *AppConfig)) {
+	next, err := loadFrom(source)
+	if err != nil {
+		logrus.Errorf("failed to reload config: %v", err)
+		return
+	}
+
+	err = prepare(next)
+	if err != nil {
+		logrus.Errorf("rejected config reload: %v", err)
+		return
+	}
+
+	previous := Current()
+	for _, name := range keepRestartFields(previous, next) {
+		logrus.Warnf("config %s changed but only takes effect after a restart", name)
+	}
+
+	// the kept values must still fit the settings that did change
+	err = validate(next)
+	if err != nil {
+		logrus.Errorf("rejected config reload: %v", err)
+		return
+	}
+	if reflect.DeepEqual(previous, next) {
+		return
+	}
+
+	current.Store(next)
+	logrus.Info("config reloaded")
+	if onChange != nil {
+		onChange(previous, next)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeepRestartFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*AppConfig)
+		changed []string
+		kept    func(*AppConfig) any
+	}{
+		{name: "no change", change: func(*AppConfig) {}},
+		{
+			name:    "database",
+			change:  func(c *AppConfig) { c.Database.Host = "other" },
+			changed: []string{"database"},
+			kept:    func(c *AppConfig) any { return c.Database.Host },
+		},
+		{
+			name:    "nonce cache size",
+			change:  func(c *AppConfig) { c.APISignature.NonceCacheSize = 10 },
+			changed: []string{"apiSignature.nonceCacheSize"},
+			kept:    func(c *AppConfig) any { return c.APISignature.NonceCacheSize },
+		},
+		{
+			name:    "gcs bucket",
+			change:  func(c *AppConfig) { c.GCSBucketName = "other" },
+			changed: []string{"gcs"},
+			kept:    func(c *AppConfig) any { return c.GCSBucketName },
+		},
+		{
+			name:   "reloadable setting",
+			change: func(c *AppConfig) { c.APISignature.MaxSkewSeconds = 60 },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := &AppConfig{Database: Database{Host: "localhost"}, GCSBucketName: "images"}
+			next := *previous
+			test.change(&next)
+
+			changed := keepRestartFields(previous, &next)
+			if !reflect.DeepEqual(changed, test.changed) {
+				t.Fatalf("keepRestartFields() = %q, want %q", changed, test.changed)
+			}
+			if test.kept != nil && !reflect.DeepEqual(test.kept(&next), test.kept(previous)) {
+				t.Fatalf("next has %v, want the running %v", test.kept(&next), test.kept(previous))
+			}
+			if test.name == "reloadable setting" && next.APISignature.MaxSkewSeconds != 60 {
+				t.Fatalf("maxSkewSeconds = %d, want the reloaded 60", next.APISignature.MaxSkewSeconds)
+			}
+		})
+	}
+}
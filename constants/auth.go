@@ -1,5 +1,13 @@
 package constants
 
 const (
-	Token = "token"
+	Token     = "token"
+	User      = "user"
+	RequestID = "requestID"
+)
+
+const (
+	AuthStrategyRemote    = "remote"
+	AuthStrategyJWT       = "jwt"
+	AuthStrategyJWTRemote = "jwt+remote"
 )
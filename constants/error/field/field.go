@@ -3,9 +3,35 @@ package error
 import "errors"
 
 var (
-	ErrFieldNotFound = errors.New("field not found")
+	ErrFieldNotFound      = errors.New("field not found")
+	ErrFieldImageNotFound = errors.New("field image not found")
+	ErrInvalidImageOrder  = errors.New("image order must list every image of the field exactly once")
+	ErrUploadNotFound     = errors.New("uploaded image not found")
+	ErrFieldCodeExists    = errors.New("field code already exists")
+	ErrFieldCodeAmbiguous = errors.New("field code is used by several venues, pass venueID")
+	ErrFieldHasBookings   = errors.New("field has future bookings, pass force=true to cancel them")
+	ErrFieldPriceNotFound = errors.New("field price not found")
+	ErrPriceInPast        = errors.New("price changes can only take effect from today on")
+	ErrPriceNotScheduled  = errors.New("only price changes that have not taken effect can be cancelled")
+	ErrInvalidFieldStatus = errors.New("field can't move to that status from its current one")
+	ErrInvalidReopenDate  = errors.New("reopen date must be after today")
+	ErrFieldUnavailable   = errors.New("field is not open for booking")
+	ErrFieldTrashExpired  = errors.New("field has been in the trash longer than the retention period and can't be restored")
 )
 
 var FieldErrors = []error{
 	ErrFieldNotFound,
+	ErrFieldImageNotFound,
+	ErrInvalidImageOrder,
+	ErrUploadNotFound,
+	ErrFieldCodeExists,
+	ErrFieldCodeAmbiguous,
+	ErrFieldHasBookings,
+	ErrFieldPriceNotFound,
+	ErrPriceInPast,
+	ErrPriceNotScheduled,
+	ErrInvalidFieldStatus,
+	ErrInvalidReopenDate,
+	ErrFieldUnavailable,
+	ErrFieldTrashExpired,
 }
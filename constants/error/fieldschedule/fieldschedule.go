@@ -5,9 +5,13 @@ import "errors"
 var (
 	ErrFieldScheduleNotFound = errors.New("field schedule not found")
 	ErrFieldScheduleIsExist  = errors.New("field schedule already exist")
+	ErrFieldScheduleBlocked  = errors.New("field schedule is not available for booking")
+	ErrFieldScheduleBooked   = errors.New("field schedule is already booked")
 )
 
 var FieldScheduleErrors = []error{
 	ErrFieldScheduleNotFound,
 	ErrFieldScheduleIsExist,
+	ErrFieldScheduleBlocked,
+	ErrFieldScheduleBooked,
 }
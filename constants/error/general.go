@@ -16,6 +16,7 @@ var (
 	ErrForbidden           = errors.New("forbidden")
 	ErrInvalidUploadFile   = errors.New("invalid upload files")
 	ErrSizeTooBig          = errors.New("size too big")
+	ErrUnsupportedImage    = errors.New("unsupported image type")
 )
 
 var GeneralErrors = []error{
@@ -25,4 +26,7 @@ var GeneralErrors = []error{
 	ErrUnauthorize,
 	ErrInvalidToken,
 	ErrForbidden,
+	ErrInvalidUploadFile,
+	ErrSizeTooBig,
+	ErrUnsupportedImage,
 }
@@ -6,19 +6,27 @@ type FieldScheduleStatus int
 const (
 	Available FieldScheduleStatus = 100
 	Booked    FieldScheduleStatus = 200
+	Blocked   FieldScheduleStatus = 300
 
 	AvailableString FieldScheduleStatusName = "Available"
 	BookedString    FieldScheduleStatusName = "Booked"
+	BlockedString   FieldScheduleStatusName = "Blocked"
 )
 
+// BlockReasonFieldStatus marks slots blocked because their field is in maintenance
+// or archived. Reactivating the field only makes these available again.
+const BlockReasonFieldStatus = "field_status"
+
 var mapFieldScheduleStatusIntToString = map[FieldScheduleStatus]FieldScheduleStatusName{
 	Available: AvailableString,
 	Booked:    BookedString,
+	Blocked:   BlockedString,
 }
 
 var mapFieldScheduleStatusStringToInt = map[FieldScheduleStatusName]FieldScheduleStatus{
 	AvailableString: Available,
 	BookedString:    Booked,
+	BlockedString:   Blocked,
 }
 
 func (f FieldScheduleStatus) GetStatusString() FieldScheduleStatusName {
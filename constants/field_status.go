@@ -0,0 +1,38 @@
+package constants
+
+type FieldStatusName string
+type FieldStatus int
+
+const (
+	Draft       FieldStatus = 100
+	Active      FieldStatus = 200
+	Maintenance FieldStatus = 300
+	Archived    FieldStatus = 400
+
+	DraftString       FieldStatusName = "Draft"
+	ActiveString      FieldStatusName = "Active"
+	MaintenanceString FieldStatusName = "Maintenance"
+	ArchivedString    FieldStatusName = "Archived"
+)
+
+var mapFieldStatusIntToString = map[FieldStatus]FieldStatusName{
+	Draft:       DraftString,
+	Active:      ActiveString,
+	Maintenance: MaintenanceString,
+	Archived:    ArchivedString,
+}
+
+var mapFieldStatusStringToInt = map[FieldStatusName]FieldStatus{
+	DraftString:       Draft,
+	ActiveString:      Active,
+	MaintenanceString: Maintenance,
+	ArchivedString:    Archived,
+}
+
+func (f FieldStatus) GetStatusString() FieldStatusName {
+	return mapFieldStatusIntToString[f]
+}
+
+func (f FieldStatusName) GetStatusInt() FieldStatus {
+	return mapFieldStatusStringToInt[f]
+}
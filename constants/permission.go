@@ -0,0 +1,16 @@
+package constants
+
+type Permission string
+
+const (
+	FieldRead     Permission = "field:read"
+	FieldWrite    Permission = "field:write"
+	ScheduleRead  Permission = "schedule:read"
+	ScheduleWrite Permission = "schedule:write"
+	TimeRead      Permission = "time:read"
+	TimeWrite     Permission = "time:write"
+)
+
+// VenueScope marks a grant that only applies to resources of the user's own venue,
+// e.g. "schedule:write@venue".
+const VenueScope = "@venue"
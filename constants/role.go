@@ -1,6 +1,8 @@
 package constants
 
 const (
-	Admin    = "admin"
-	Customer = "customer"
+	Admin        = "admin"
+	Customer     = "customer"
+	VenueManager = "venue-manager"
+	Support      = "support"
 )
@@ -20,9 +20,24 @@ type IFieldController interface {
 	GetAllWithPagination(*gin.Context)
 	GetAllWithoutPagination(*gin.Context)
 	GetByUUID(*gin.Context)
+	GetByCode(*gin.Context)
 	Create(*gin.Context)
 	Update(*gin.Context)
 	Delete(*gin.Context)
+	AddImage(*gin.Context)
+	UpdateImage(*gin.Context)
+	DeleteImage(*gin.Context)
+	ReorderImages(*gin.Context)
+	SetCoverImage(*gin.Context)
+	CreateImageUploadURL(*gin.Context)
+	ConfirmImageUpload(*gin.Context)
+	GetTrash(*gin.Context)
+	Restore(*gin.Context)
+	PurgeTrash(*gin.Context)
+	GetPrices(*gin.Context)
+	SchedulePrice(*gin.Context)
+	CancelPrice(*gin.Context)
+	UpdateStatus(*gin.Context)
 }
 
 func NewFieldController(service services.IServiceRegistry) IFieldController {
@@ -111,6 +126,51 @@ func (f *FieldController) GetByUUID(ctx *gin.Context) {
 	})
 }
 
+func (f *FieldController) GetByCode(ctx *gin.Context) {
+	var params = dto.FieldCodeRequestParam{}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	validate := validator.New()
+	err = validate.Struct(params)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := f.service.GetField().GetByCode(ctx, ctx.Param("code"), &params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
+
 func (f *FieldController) Create(ctx *gin.Context) {
 	request := dto.FieldRequest{}
 	err := ctx.ShouldBindWith(&request, binding.FormMultipart)
@@ -202,8 +262,20 @@ func (f *FieldController) Update(ctx *gin.Context) {
 }
 
 func (f *FieldController) Delete(ctx *gin.Context) {
+	var params = dto.DeleteFieldRequestParam{}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
 	uuid := ctx.Param("uuid")
-	err := f.service.GetField().Delete(ctx, uuid)
+	err = f.service.GetField().Delete(ctx, uuid, &params)
 	if err != nil {
 		response.HttpResponse(response.ParamHTTPResp{
 			Code: http.StatusBadRequest,
@@ -218,3 +290,84 @@ func (f *FieldController) Delete(ctx *gin.Context) {
 		Gin:  ctx,
 	})
 }
+
+func (f *FieldController) GetTrash(ctx *gin.Context) {
+	var params = dto.TrashRequestParam{}
+
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	validate := validator.New()
+	err = validate.Struct(params)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errorResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Err:     err,
+			Message: &errMessage,
+			Data:    errorResponse,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := f.service.GetField().GetTrash(ctx, &params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
+
+func (f *FieldController) Restore(ctx *gin.Context) {
+	result, err := f.service.GetField().Restore(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
+
+func (f *FieldController) PurgeTrash(ctx *gin.Context) {
+	result, err := f.service.GetField().PurgeTrash(ctx)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
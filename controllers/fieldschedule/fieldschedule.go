@@ -24,6 +24,8 @@ type IFieldScheduleController interface {
 	UpdateStatus(*gin.Context)
 	Delete(*gin.Context)
 	GenerateScheduleForOneMonth(*gin.Context)
+	GetTrash(*gin.Context)
+	Restore(*gin.Context)
 }
 
 func NewFieldScheduleController(service services.IServiceRegistry) IFieldScheduleController {
@@ -333,3 +335,63 @@ func (fs *FieldScheduleController) GenerateScheduleForOneMonth(ctx *gin.Context)
 		Gin:  ctx,
 	})
 }
+
+func (fs *FieldScheduleController) GetTrash(ctx *gin.Context) {
+	var params dto.TrashRequestParam
+	err := ctx.ShouldBindQuery(&params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	validate := validator.New()
+	err = validate.Struct(params)
+	if err != nil {
+		errMessage := http.StatusText(http.StatusUnprocessableEntity)
+		errResponse := errValidation.ErrValidationResponse(err)
+		response.HttpResponse(response.ParamHTTPResp{
+			Code:    http.StatusBadRequest,
+			Message: &errMessage,
+			Data:    errResponse,
+			Err:     err,
+			Gin:     ctx,
+		})
+		return
+	}
+
+	result, err := fs.service.GetFieldSchedule().GetTrash(ctx, &params)
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
+
+func (fs *FieldScheduleController) Restore(ctx *gin.Context) {
+	result, err := fs.service.GetFieldSchedule().Restore(ctx, ctx.Param("uuid"))
+	if err != nil {
+		response.HttpResponse(response.ParamHTTPResp{
+			Code: http.StatusBadRequest,
+			Err:  err,
+			Gin:  ctx,
+		})
+		return
+	}
+
+	response.HttpResponse(response.ParamHTTPResp{
+		Code: http.StatusOK,
+		Data: result,
+		Gin:  ctx,
+	})
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/google/uuid"
+
+type FieldDeletedEvent struct {
+	FieldUUID          uuid.UUID `json:"fieldUUID"`
+	Code               string    `json:"code"`
+	Forced             bool      `json:"forced"`
+	CancelledSchedules int       `json:"cancelledSchedules"`
+}
+
+type BookingCancelledEvent struct {
+	FieldUUID    uuid.UUID `json:"fieldUUID"`
+	ScheduleUUID uuid.UUID `json:"scheduleUUID"`
+	Date         string    `json:"date"`
+	Time         string    `json:"time"`
+	PricePerHour int       `json:"pricePerHour"`
+	Reason       string    `json:"reason"`
+}
@@ -5,12 +5,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/thomzes/field-service-booking-app/constants"
 )
 
+// FieldRequest creates a field. It starts as a Draft, hidden from the public
+// endpoints, unless Status is Active.
 type FieldRequest struct {
 	Name         string                 `form:"name" validate:"required"`
 	Code         string                 `form:"code" validate:"required"`
 	PricePerHour int                    `form:"pricePerHour" validate:"required"`
+	VenueID      string                 `form:"venueID" validate:"omitempty,uuid"`
+	Status       string                 `form:"status" validate:"omitempty,oneof=Draft Active"`
 	Images       []multipart.FileHeader `form:"images" validate:"required"`
 }
 
@@ -18,31 +23,69 @@ type UpdateFieldRequest struct {
 	Name         string                 `form:"name" validate:"required"`
 	Code         string                 `form:"code" validate:"required"`
 	PricePerHour int                    `form:"pricePerHour" validate:"required"`
+	VenueID      string                 `form:"venueID" validate:"omitempty,uuid"`
 	Images       []multipart.FileHeader `form:"images"`
 }
 
 type FieldResponse struct {
-	UUID         uuid.UUID  `json:"uuid"`
-	Code         string     `json:"code"`
-	Name         string     `json:"name"`
-	PricePerHour any        `json:"pericePerHour"`
-	Images       []string   `json:"images"`
-	CreatedAt    *time.Time `json:"createdAt"`
-	UpdatedAt    *time.Time `json:"updatedAt"`
+	UUID              uuid.UUID                 `json:"uuid"`
+	VenueID           *uuid.UUID                `json:"venueID"`
+	Code              string                    `json:"code"`
+	Name              string                    `json:"name"`
+	PricePerHour      any                       `json:"pericePerHour"`
+	Status            constants.FieldStatusName `json:"status"`
+	MaintenanceReason string                    `json:"maintenanceReason,omitempty"`
+	ReopenAt          *string                   `json:"reopenAt,omitempty"`
+	Images            []FieldImageResponse      `json:"images"`
+	CreatedAt         *time.Time                `json:"createdAt"`
+	UpdatedAt         *time.Time                `json:"updatedAt"`
+	DeletedAt         *time.Time                `json:"deletedAt,omitempty"`
+}
+
+// UpdateFieldStatusRequest moves a field through its lifecycle. Reason is
+// required for Maintenance; ReopenAt is the day the field is expected to open
+// again, slots before it are blocked.
+type UpdateFieldStatusRequest struct {
+	Status   string `json:"status" validate:"required,oneof=Draft Active Maintenance Archived"`
+	Reason   string `json:"reason" validate:"required_if=Status Maintenance,max=255"`
+	ReopenAt string `json:"reopenAt" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// FieldStatusResponse reports the schedules a status change touched.
+// BookedSchedules are bookings inside the closure that still stand.
+type FieldStatusResponse struct {
+	Field              *FieldResponse `json:"field"`
+	BlockedSchedules   int64          `json:"blockedSchedules"`
+	UnblockedSchedules int64          `json:"unblockedSchedules"`
+	BookedSchedules    int64          `json:"bookedSchedules"`
 }
 
 type FieldDetailResponse struct {
-	Code         string     `json:"code"`
-	Name         string     `json:"name"`
-	PricePerHour string     `json:"pericePerHour"`
-	Images       []string   `json:"images"`
-	CreatedAt    *time.Time `json:"createdAt"`
-	UpdatedAt    *time.Time `json:"updatedAt"`
+	Code         string               `json:"code"`
+	Name         string               `json:"name"`
+	PricePerHour string               `json:"pericePerHour"`
+	Images       []FieldImageResponse `json:"images"`
+	CreatedAt    *time.Time           `json:"createdAt"`
+	UpdatedAt    *time.Time           `json:"updatedAt"`
+}
+
+type FieldCodeRequestParam struct {
+	VenueID string `form:"venueID" validate:"omitempty,uuid"`
+}
+
+// DeleteFieldRequestParam forces the delete of a field with future bookings,
+// releasing them.
+type DeleteFieldRequestParam struct {
+	Force bool `form:"force"`
 }
 
+// FieldRequestParam pages through the fields. Statuses is set by the service
+// to the statuses the user may see, nil when every status is visible.
 type FieldRequestParam struct {
-	Page       int     `form:"page" validate:"required"`
-	Limit      int     `form:"limit" validate:"required"`
-	SortColumn *string `form:"sortColumn"`
-	SortOrder  *string `form:"sortOrder"`
+	Page       int                     `form:"page" validate:"required"`
+	Limit      int                     `form:"limit" validate:"required"`
+	Status     *string                 `form:"status" validate:"omitempty,oneof=Draft Active Maintenance Archived"`
+	SortColumn *string                 `form:"sortColumn"`
+	SortOrder  *string                 `form:"sortOrder"`
+	Statuses   []constants.FieldStatus `form:"-"`
 }
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldImageRequest struct {
+	Image   *multipart.FileHeader `form:"image" validate:"required"`
+	AltText string                `form:"altText" validate:"max=255"`
+}
+
+type UpdateFieldImageRequest struct {
+	AltText string `json:"altText" validate:"max=255"`
+}
+
+// FieldImageUploadURLRequest asks for a URL to upload an image straight to
+// storage. Size is the length of the file in bytes.
+type FieldImageUploadURLRequest struct {
+	ContentType string `json:"contentType" validate:"required,oneof=image/jpeg image/png image/gif image/webp"`
+	Size        int64  `json:"size" validate:"required,gt=0"`
+}
+
+// FieldImageUploadURLResponse tells the client where to upload the image.
+// A POST is a multipart form with Fields before the file, a PUT sends the file
+// as the body with Headers.
+type FieldImageUploadURLResponse struct {
+	UploadID  uuid.UUID         `json:"uploadID"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// ConfirmFieldImageUploadRequest attaches an image uploaded to the URL of
+// UploadID.
+type ConfirmFieldImageUploadRequest struct {
+	UploadID string `json:"uploadID" validate:"required,uuid"`
+	AltText  string `json:"altText" validate:"max=255"`
+}
+
+// ReorderFieldImagesRequest lists every image of the field in its new order.
+type ReorderFieldImagesRequest struct {
+	ImageUUIDs []string `json:"imageUUIDs" validate:"required,min=1,dive,uuid"`
+}
+
+// FieldImageResponse has one entry in Sizes per generated size: "thumbnail",
+// "card" and "full".
+type FieldImageResponse struct {
+	UUID     uuid.UUID                    `json:"uuid"`
+	AltText  string                       `json:"altText"`
+	IsCover  bool                         `json:"isCover"`
+	Width    int                          `json:"width"`
+	Height   int                          `json:"height"`
+	BlurHash string                       `json:"blurhash"`
+	Sizes    map[string]ImageSizeResponse `json:"sizes"`
+}
+
+type ImageSizeResponse struct {
+	URL     string `json:"url"`
+	WebPURL string `json:"webpURL,omitempty"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldPriceRequest changes the price of a field from EffectiveFrom on, today
+// or later.
+type FieldPriceRequest struct {
+	PricePerHour  int    `json:"pricePerHour" validate:"required,gt=0"`
+	EffectiveFrom string `json:"effectiveFrom" validate:"required,datetime=2006-01-02"`
+}
+
+// FieldPriceRequestParam narrows the history to the price effective on Date.
+type FieldPriceRequestParam struct {
+	Date string `form:"date" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type FieldPriceResponse struct {
+	UUID          uuid.UUID  `json:"uuid"`
+	PricePerHour  int        `json:"pricePerHour"`
+	EffectiveFrom string     `json:"effectiveFrom"`
+	EffectiveTo   *string    `json:"effectiveTo"`
+	CreatedAt     *time.Time `json:"createdAt"`
+	UpdatedAt     *time.Time `json:"updatedAt"`
+}
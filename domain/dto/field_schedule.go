@@ -35,6 +35,7 @@ type FieldScheduleResponse struct {
 	Time         string                            `json:"time"`
 	CreatedAt    *time.Time                        `json:"createdAt"`
 	UpdatedAt    *time.Time                        `json:"updatedAt"`
+	DeletedAt    *time.Time                        `json:"deletedAt,omitempty"`
 }
 
 type FieldScheduleForBookingResponse struct {
@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/google/uuid"
+
+// TrashRequestParam pages through the trash. VenueID is set by the service for
+// users whose grant is scoped to their venue.
+type TrashRequestParam struct {
+	Page    int        `form:"page" validate:"required,min=1"`
+	Limit   int        `form:"limit" validate:"required,min=1"`
+	VenueID *uuid.UUID `form:"-"`
+}
+
+// PurgeTrashResponse counts the rows deleted for good. Schedules of purged
+// fields are deleted with them and not counted.
+type PurgeTrashResponse struct {
+	Fields    int64 `json:"fields"`
+	Schedules int64 `json:"schedules"`
+}
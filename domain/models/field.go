@@ -4,19 +4,47 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq"
+	"github.com/thomzes/field-service-booking-app/constants"
 	"gorm.io/gorm"
 )
 
+// Field is a bookable field. MaintenanceReason and ReopenAt describe the
+// closure while its status is Maintenance.
 type Field struct {
-	ID            uint           `gorm:"primaryKey;autoIncrement"`
-	UUID          uuid.UUID      `gorm:"type:uuid;not null"`
-	Code          string         `gorm:"type:varchar(15);not null"`
-	Name          string         `gorm:"type:varchar(100);not null"`
-	PricePerHour  int            `gorm:"type:int;not null"`
-	Images        pq.StringArray `gorm:"type:text[];not null"`
-	CreatedAt     *time.Time
-	UpdatedAt     *time.Time
-	DeletedAt     *gorm.DeletedAt
-	FieldSchedule []FieldSchedule `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID                uint                  `gorm:"primaryKey;autoIncrement"`
+	UUID              uuid.UUID             `gorm:"type:uuid;not null"`
+	VenueID           *uuid.UUID            `gorm:"type:uuid"`
+	Code              string                `gorm:"type:varchar(15);not null"`
+	Name              string                `gorm:"type:varchar(100);not null"`
+	Status            constants.FieldStatus `gorm:"type:int;not null"`
+	MaintenanceReason string                `gorm:"type:varchar(255);not null"`
+	ReopenAt          *time.Time            `gorm:"type:date"`
+	CreatedAt         *time.Time
+	UpdatedAt         *time.Time
+	DeletedAt         *gorm.DeletedAt
+	FieldSchedule     []FieldSchedule `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Images            []FieldImage    `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Prices            []FieldPrice    `gorm:"foreignKey:field_id;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// PriceAt returns the price effective on the day of date. Days before the
+// first price use the first price. Prices must be loaded ordered by
+// EffectiveFrom; nil is returned when none are.
+func (f Field) PriceAt(date time.Time) *FieldPrice {
+	day := date.Format(time.DateOnly)
+	for i := len(f.Prices) - 1; i >= 0; i-- {
+		if i == 0 || f.Prices[i].EffectiveFrom.Format(time.DateOnly) <= day {
+			return &f.Prices[i]
+		}
+	}
+	return nil
+}
+
+// PriceOn returns the price per hour effective on the day of date.
+func (f Field) PriceOn(date time.Time) int {
+	price := f.PriceAt(date)
+	if price == nil {
+		return 0
+	}
+	return price.PricePerHour
 }
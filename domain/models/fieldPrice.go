@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldPrice is the price per hour of a field from EffectiveFrom up to the
+// day before EffectiveTo. The latest price has no EffectiveTo.
+type FieldPrice struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement"`
+	UUID          uuid.UUID  `gorm:"type:uuid;not null"`
+	FieldID       uint       `gorm:"type:int;not null"`
+	PricePerHour  int        `gorm:"type:int;not null"`
+	EffectiveFrom time.Time  `gorm:"type:date;not null"`
+	EffectiveTo   *time.Time `gorm:"type:date"`
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time
+}
@@ -5,18 +5,24 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/thomzes/field-service-booking-app/constants"
+	"gorm.io/gorm"
 )
 
+// FieldSchedule is one bookable slot of a field. PricePerHour is the price
+// the slot was booked at and stays nil while it is available. BlockReason
+// says why a blocked slot is blocked.
 type FieldSchedule struct {
-	ID        uint                          `gorm:"primaryKey;autoIncrement"`
-	UUID      uuid.UUID                     `gorm:"type:uuid;not null"`
-	FieldID   uint                          `gorm:"type:int;not null"`
-	TimeID    uint                          `gorm:"type:int;not null"`
-	Date      time.Time                     `gorm:"type:date; not null"`
-	Status    constants.FieldScheduleStatus `gorm:"type:int;not null"`
-	CreatedAt *time.Time
-	UpdatedAt *time.Time
-	DeletedAt *time.Time
-	Field     Field `gorm:"foreignKey:field_id;references:id;constraint:onUpdate:CASCADE, onDelete:CASCADE"`
-	Time      Time  `gorm:"foreignKey:time_id;references:id;constraint:onUpdate:CASCADE, onDelete:CASCADE"`
+	ID           uint                          `gorm:"primaryKey;autoIncrement"`
+	UUID         uuid.UUID                     `gorm:"type:uuid;not null"`
+	FieldID      uint                          `gorm:"type:int;not null"`
+	TimeID       uint                          `gorm:"type:int;not null"`
+	Date         time.Time                     `gorm:"type:date; not null"`
+	Status       constants.FieldScheduleStatus `gorm:"type:int;not null"`
+	PricePerHour *int                          `gorm:"type:int"`
+	BlockReason  string                        `gorm:"type:varchar(50);not null;default:''"`
+	CreatedAt    *time.Time
+	UpdatedAt    *time.Time
+	DeletedAt    *gorm.DeletedAt
+	Field        Field `gorm:"foreignKey:field_id;references:id;constraint:onUpdate:CASCADE, onDelete:CASCADE"`
+	Time         Time  `gorm:"foreignKey:time_id;references:id;constraint:onUpdate:CASCADE, onDelete:CASCADE"`
 }
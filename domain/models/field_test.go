@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func TestFieldPriceOn(t *testing.T) {
+	to := date("2026-03-01")
+	field := Field{Prices: []FieldPrice{
+		{PricePerHour: 100000, EffectiveFrom: date("2026-01-01"), EffectiveTo: &to},
+		{PricePerHour: 120000, EffectiveFrom: date("2026-03-01")},
+	}}
+
+	tests := []struct {
+		name  string
+		field Field
+		date  time.Time
+		price int
+	}{
+		{name: "no prices", field: Field{}, date: date("2026-02-01"), price: 0},
+		{name: "before the first price", field: field, date: date("2025-12-31"), price: 100000},
+		{name: "first day of the first price", field: field, date: date("2026-01-01"), price: 100000},
+		{name: "last day before the change", field: field, date: date("2026-02-28"), price: 100000},
+		{name: "day of the change", field: field, date: date("2026-03-01"), price: 120000},
+		{name: "after the latest price starts", field: field, date: date("2027-01-01"), price: 120000},
+		{name: "time of day is ignored", field: field, date: date("2026-02-28").Add(23*time.Hour + 59*time.Minute), price: 100000},
+		{name: "change starts at midnight", field: field, date: date("2026-03-01").Add(time.Second), price: 120000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			price := test.field.PriceOn(test.date)
+			if price != test.price {
+				t.Fatalf("PriceOn(%s) = %d, want %d", test.date, price, test.price)
+			}
+		})
+	}
+}
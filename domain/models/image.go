@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldImage is one uploaded image of a field with a URL per generated size.
+// Images stored before the pipeline existed only have the "full" size and no
+// hash.
+type FieldImage struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	UUID      uuid.UUID  `gorm:"type:uuid;not null"`
+	FieldID   uint       `gorm:"type:int;not null"`
+	Position  int        `gorm:"type:int;not null"`
+	AltText   string     `gorm:"type:varchar(255);not null"`
+	IsCover   bool       `gorm:"not null"`
+	Hash      string     `gorm:"type:varchar(64);not null"`
+	Width     int        `gorm:"type:int;not null"`
+	Height    int        `gorm:"type:int;not null"`
+	BlurHash  string     `gorm:"column:blurhash;type:varchar(64);not null"`
+	Sizes     ImageSizes `gorm:"type:jsonb;not null"`
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+type ImageSize struct {
+	URL     string `json:"url"`
+	WebPURL string `json:"webpURL,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+}
+
+// URLs returns every stored URL of the image.
+func (f FieldImage) URLs() []string {
+	urls := make([]string, 0, len(f.Sizes)*2)
+	for _, size := range f.Sizes {
+		if size.URL != "" {
+			urls = append(urls, size.URL)
+		}
+		if size.WebPURL != "" {
+			urls = append(urls, size.WebPURL)
+		}
+	}
+	return urls
+}
+
+// ImageSizes is keyed by size name and stored as a jsonb object.
+type ImageSizes map[string]ImageSize
+
+func (i ImageSizes) Value() (driver.Value, error) {
+	if i == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (i *ImageSizes) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*i = ImageSizes{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ImageSizes", value)
+	}
+	return json.Unmarshal(data, i)
+}
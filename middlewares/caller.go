@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/thomzes/field-service-booking-app/config"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+)
+
+// callerSignatureKeys returns the keys a caller may sign with, active key first.
+// Without a caller registry every caller shares config.Current().SignatureKey.
+// An unknown caller, or one without any key, can't be verified.
+func callerSignatureKeys(serviceName string) ([]string, error) {
+	callers := config.Current().CallerServices
+	if len(callers) == 0 {
+		return []string{config.Current().SignatureKey}, nil
+	}
+
+	for _, caller := range callers {
+		if caller.Name != serviceName {
+			continue
+		}
+
+		keys := make([]string, 0, len(caller.PreviousKeys)+1)
+		if caller.ActiveKey != "" {
+			keys = append(keys, caller.ActiveKey)
+		}
+		for _, key := range caller.PreviousKeys {
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, errConstant.ErrUnauthorize
+		}
+
+		return keys, nil
+	}
+
+	return nil, errConstant.ErrUnauthorize
+}
+
+// checkCallerRoute rejects a verified caller that is not allowed to call the route.
+// Without a caller registry every route is allowed.
+func checkCallerRoute(serviceName, method, route string) error {
+	for _, caller := range config.Current().CallerServices {
+		if caller.Name == serviceName && !isRouteAllowed(caller.AllowedRoutes, method, route) {
+			return errConstant.ErrForbidden
+		}
+	}
+
+	return nil
+}
+
+// isRouteAllowed matches "METHOD /route/template" patterns against the gin route.
+// "*" matches any method or any route, and a trailing "*" matches a route prefix.
+func isRouteAllowed(patterns []string, method, route string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+
+		patternMethod, patternRoute, found := strings.Cut(pattern, " ")
+		if !found {
+			continue
+		}
+
+		if patternMethod != "*" && !strings.EqualFold(patternMethod, method) {
+			continue
+		}
+
+		if patternRoute == "*" || patternRoute == route {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(patternRoute, "*"); ok && strings.HasPrefix(route, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
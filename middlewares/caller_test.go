@@ -0,0 +1,36 @@
+package middlewares
+
+import "testing"
+
+func TestIsRouteAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		method   string
+		route    string
+		allowed  bool
+	}{
+		{name: "no patterns", method: "GET", route: "/api/v1/field"},
+		{name: "wildcard", patterns: []string{"*"}, method: "DELETE", route: "/api/v1/field/:uuid", allowed: true},
+		{name: "exact route", patterns: []string{"GET /api/v1/field/:uuid"}, method: "GET", route: "/api/v1/field/:uuid", allowed: true},
+		{name: "method is case insensitive", patterns: []string{"get /api/v1/field"}, method: "GET", route: "/api/v1/field", allowed: true},
+		{name: "other method", patterns: []string{"GET /api/v1/field"}, method: "POST", route: "/api/v1/field"},
+		{name: "any method", patterns: []string{"* /api/v1/field"}, method: "POST", route: "/api/v1/field", allowed: true},
+		{name: "any route", patterns: []string{"PATCH *"}, method: "PATCH", route: "/api/v1/field/schedule/status", allowed: true},
+		{name: "prefix", patterns: []string{"GET /api/v1/field/schedule/*"}, method: "GET", route: "/api/v1/field/schedule/lists/:uuid", allowed: true},
+		{name: "prefix does not match parent", patterns: []string{"GET /api/v1/field/schedule/*"}, method: "GET", route: "/api/v1/field"},
+		{name: "route template is not a prefix", patterns: []string{"GET /api/v1/field"}, method: "GET", route: "/api/v1/field/:uuid"},
+		{name: "pattern without method is ignored", patterns: []string{"/api/v1/field"}, method: "GET", route: "/api/v1/field"},
+		{name: "second pattern matches", patterns: []string{"GET /api/v1/time", "PATCH /api/v1/field/schedule/status"}, method: "PATCH", route: "/api/v1/field/schedule/status", allowed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			allowed := isRouteAllowed(test.patterns, test.method, test.route)
+			if allowed != test.allowed {
+				t.Fatalf("isRouteAllowed(%q, %q, %q) = %t, want %t",
+					test.patterns, test.method, test.route, allowed, test.allowed)
+			}
+		})
+	}
+}
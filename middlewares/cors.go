@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+var corsAllowHeaders = strings.Join([]string{
+	"Content-Type",
+	constants.Authorization,
+	constants.XServiceName,
+	constants.XApiKey,
+	constants.XRequestAt,
+	constants.XRequestNonce,
+	constants.XRequestID,
+}, ", ")
+
+// CORS reads the allowed origins on every request so config reloads apply
+// without a restart.
+func CORS() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowedOrigins := config.Current().CORS.AllowedOrigins
+		origin := ctx.GetHeader("Origin")
+
+		switch {
+		case len(allowedOrigins) == 0 || slices.Contains(allowedOrigins, "*"):
+			ctx.Header("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(allowedOrigins, origin):
+			ctx.Header("Access-Control-Allow-Origin", origin)
+			ctx.Header("Vary", "Origin")
+		}
+		ctx.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+		ctx.Header("Access-Control-Allow-Headers", corsAllowHeaders)
+		ctx.Header("Access-Control-Expose-Headers", constants.XRequestID)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID accepts the caller's X-Request-ID or generates one, echoes it back
+// and stores it on the context for the logger.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(constants.XRequestID)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(constants.RequestID, requestID)
+		ctx.Request = ctx.Request.WithContext(logger.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(constants.XRequestID, requestID)
+
+		ctx.Next()
+	}
+}
+
+// AccessLog writes one structured line per request. Paths in skipPaths, such
+// as probes and scrapes, are not logged.
+func AccessLog(skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(ctx *gin.Context) {
+		startedAt := time.Now()
+		ctx.Next()
+
+		if _, ok := skip[ctx.Request.URL.Path]; ok {
+			return
+		}
+
+		status := ctx.Writer.Status()
+		entry := logger.FromContext(ctx.Request.Context()).WithFields(logrus.Fields{
+			"method":     ctx.Request.Method,
+			"path":       ctx.Request.URL.Path,
+			"route":      ctx.FullPath(),
+			"status":     status,
+			"latency_ms": time.Since(startedAt).Milliseconds(),
+			"client_ip":  ctx.ClientIP(),
+			"user_agent": ctx.Request.UserAgent(),
+			"bytes":      ctx.Writer.Size(),
+		})
+		if logrus.IsLevelEnabled(logrus.DebugLevel) {
+			entry = entry.WithField("headers", logger.RedactHeaders(ctx.Request.Header))
+		}
+		if len(ctx.Errors) > 0 {
+			entry = entry.WithField("errors", ctx.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			entry.Error("request completed")
+		case status >= 400:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, char := range requestID {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,32 +1,47 @@
 package middlewares
 
 import (
+	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/didip/tollbooth"
 	"github.com/didip/tollbooth/limiter"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 	"github.com/thomzes/field-service-booking-app/clients"
+	"github.com/thomzes/field-service-booking-app/common/cache"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
+	"github.com/thomzes/field-service-booking-app/common/policy"
 	"github.com/thomzes/field-service-booking-app/common/response"
+	"github.com/thomzes/field-service-booking-app/common/signature"
+	"github.com/thomzes/field-service-booking-app/common/tracing"
 	"github.com/thomzes/field-service-booking-app/config"
 	"github.com/thomzes/field-service-booking-app/constants"
 	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
 )
 
+var (
+	nonceCache     cache.ICache
+	nonceCacheOnce sync.Once
+)
+
 func HandlePanic() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logrus.Errorf("Recover from panic: %v", err)
+				logger.FromContext(ctx.Request.Context()).Errorf("Recover from panic: %v", err)
 				ctx.JSON(http.StatusInternalServerError, response.Response{
 					Status:  constants.Error,
 					Message: errConstant.ErrInternalServerError.Error(),
+					TraceID: tracing.TraceID(ctx.Request.Context()),
 				})
 				ctx.Abort()
 			}
@@ -40,9 +55,11 @@ func RateLimiter(lmt *limiter.Limiter) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		err := tollbooth.LimitByRequest(lmt, ctx.Writer, ctx.Request)
 		if err != nil {
+			metrics.RateLimitRejections.Inc()
 			ctx.JSON(http.StatusTooManyRequests, response.Response{
 				Status:  constants.Error,
 				Message: errConstant.ErrToManyRequest.Error(),
+				TraceID: tracing.TraceID(ctx.Request.Context()),
 			})
 			ctx.Abort()
 		}
@@ -64,52 +81,156 @@ func responseUnauthorize(ctx *gin.Context, message string) {
 	ctx.JSON(http.StatusUnauthorized, response.Response{
 		Status:  constants.Error,
 		Message: message,
+		TraceID: tracing.TraceID(ctx.Request.Context()),
+	})
+	ctx.Abort()
+}
+
+func responseForbidden(ctx *gin.Context, message string) {
+	ctx.JSON(http.StatusForbidden, response.Response{
+		Status:  constants.Error,
+		Message: message,
+		TraceID: tracing.TraceID(ctx.Request.Context()),
 	})
 	ctx.Abort()
 }
 
+func responseAPIKeyError(ctx *gin.Context, err error) {
+	if errors.Is(err, errConstant.ErrForbidden) {
+		responseForbidden(ctx, err.Error())
+		return
+	}
+	responseUnauthorize(ctx, err.Error())
+}
+
+func nonceStore() cache.ICache {
+	nonceCacheOnce.Do(func() {
+		nonceCache = cache.NewLRUCache(config.Current().APISignature.NonceCacheSize)
+	})
+	return nonceCache
+}
+
+func isRequestAtValid(requestAt string) bool {
+	unixTime, err := strconv.ParseInt(requestAt, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	maxSkew := time.Duration(config.Current().APISignature.MaxSkewSeconds) * time.Second
+	if maxSkew <= 0 {
+		return true
+	}
+
+	skew := time.Since(time.Unix(unixTime, 0))
+	return skew <= maxSkew && skew >= -maxSkew
+}
+
+func readBody(ctx *gin.Context) ([]byte, error) {
+	if ctx.Request.Body == nil {
+		return nil, nil
+	}
+
+	maxBodyBytes := int64(config.Current().APISignature.MaxBodyBytes)
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func isLegacySignatureValid(signatureKeys []string, serviceName, requestAt, apiKey string) bool {
+	for _, signatureKey := range signatureKeys {
+		if signature.Equal(signature.SignLegacy(signatureKey, serviceName, requestAt), apiKey) {
+			return true
+		}
+	}
+	return false
+}
+
 func validateAPIKey(ctx *gin.Context) error {
 	apiKey := ctx.GetHeader(constants.XApiKey)
 	requestAt := ctx.GetHeader(constants.XRequestAt)
 	serviceName := ctx.GetHeader(constants.XServiceName)
-	signatureKey := config.Config.SignatureKey
-
-	validateKey := fmt.Sprintf("%s:%s:%s", serviceName, signatureKey, requestAt)
-	hash := sha256.New()
-	hash.Write([]byte(validateKey))
-	resultHash := hex.EncodeToString(hash.Sum(nil))
+	nonce := ctx.GetHeader(constants.XRequestNonce)
 
-	if apiKey != resultHash {
+	if !isRequestAtValid(requestAt) {
 		return errConstant.ErrUnauthorize
 	}
 
-	return nil
-}
+	signatureKeys, err := callerSignatureKeys(serviceName)
+	if err != nil {
+		return err
+	}
 
-func contains(roles []string, role string) bool {
-	for _, r := range roles {
-		if r == role {
-			return true
+	if nonce == "" {
+		if !config.Current().APISignature.AllowLegacy || !isLegacySignatureValid(signatureKeys, serviceName, requestAt, apiKey) {
+			return errConstant.ErrUnauthorize
 		}
+		return checkCallerRoute(serviceName, ctx.Request.Method, ctx.FullPath())
 	}
-	return false
+
+	body, err := readBody(ctx)
+	if err != nil {
+		return errConstant.ErrUnauthorize
+	}
+
+	bodyHash := signature.HashBody(body)
+	isValid := false
+	for _, signatureKey := range signatureKeys {
+		expected := signature.Sign(
+			signatureKey,
+			serviceName,
+			ctx.Request.Method,
+			ctx.Request.URL.RequestURI(),
+			requestAt,
+			nonce,
+			bodyHash,
+		)
+		if signature.Equal(expected, apiKey) {
+			isValid = true
+			break
+		}
+	}
+	if !isValid {
+		return errConstant.ErrUnauthorize
+	}
+
+	err = checkCallerRoute(serviceName, ctx.Request.Method, ctx.FullPath())
+	if err != nil {
+		return err
+	}
+
+	nonceKey := fmt.Sprintf("nonce:%s:%s", serviceName, nonce)
+	nonceTTL := time.Duration(config.Current().APISignature.NonceTTLSeconds) * time.Second
+	stored, err := nonceStore().SetNX(ctx.Request.Context(), nonceKey, []byte(requestAt), nonceTTL)
+	if err != nil || !stored {
+		return errConstant.ErrUnauthorize
+	}
+
+	return nil
 }
 
-func CheckRole(roles []string, client clients.IClientRegistry) gin.HandlerFunc {
+// Authorize resolves the user behind the bearer token and lets the request through
+// when the role has the permission, globally or scoped to its venue. Venue scoped
+// grants are narrowed down to the concrete resource by policy.Authorize in the services.
+func Authorize(permission constants.Permission, client clients.IClientRegistry) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-
 		user, err := client.GetUser().GetUserByToken(ctx.Request.Context())
-
 		if err != nil {
 			responseUnauthorize(ctx, errConstant.ErrUnauthorize.Error())
 			return
 		}
 
-		if !contains(roles, user.Role) {
-			responseUnauthorize(ctx, errConstant.ErrUnauthorize.Error())
+		if policy.GrantFor(user.Role, permission) == policy.GrantNone {
+			responseForbidden(ctx, errConstant.ErrForbidden.Error())
 			return
 		}
 
+		ctx.Set(constants.User, user)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), constants.User, user))
+
 		ctx.Next()
 	}
 }
@@ -125,7 +246,7 @@ func Authenticate() gin.HandlerFunc {
 
 		err = validateAPIKey(ctx)
 		if err != nil {
-			responseUnauthorize(ctx, err.Error())
+			responseAPIKeyError(ctx, err)
 			return
 		}
 
@@ -143,7 +264,7 @@ func AuthenticateWithoutToken() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		err := validateAPIKey(ctx)
 		if err != nil {
-			responseUnauthorize(ctx, err.Error())
+			responseAPIKeyError(ctx, err)
 			return
 		}
 
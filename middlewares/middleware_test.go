@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/thomzes/field-service-booking-app/common/signature"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+const testPath = "/api/v1/field/3b1f5a0e-0c1d-4a57-9f0e-6a3c2d1b0a99"
+
+type signedRequest struct {
+	service   string
+	key       string
+	method    string
+	path      string
+	body      string
+	signed    string
+	requestAt time.Time
+	nonce     string
+}
+
+func newSignatureRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	config.Set(&config.AppConfig{
+		APISignature: config.APISignature{
+			AllowLegacy:     true,
+			MaxSkewSeconds:  300,
+			NonceTTLSeconds: 600,
+			NonceCacheSize:  100,
+			MaxBodyBytes:    1 << 10,
+		},
+		CallerServices: []config.CallerService{
+			{Name: "order-service", ActiveKey: "active", PreviousKeys: []string{"previous"}, AllowedRoutes: []string{"* /api/v1/field/:uuid"}},
+			{Name: "report-service", ActiveKey: "report", AllowedRoutes: []string{"GET /api/v1/time"}},
+			{Name: "retired-service", AllowedRoutes: []string{"*"}},
+		},
+	})
+
+	router := gin.New()
+	router.Any("/api/v1/field/:uuid", AuthenticateWithoutToken(), func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, string(body))
+	})
+	return router
+}
+
+// send signs the request like a caller would. signed overrides the body that is
+// signed to simulate a tampered body.
+func send(router *gin.Engine, request signedRequest) *httptest.ResponseRecorder {
+	if request.method == "" {
+		request.method = http.MethodPost
+	}
+	if request.path == "" {
+		request.path = testPath
+	}
+	if request.requestAt.IsZero() {
+		request.requestAt = time.Now()
+	}
+	signed := request.body
+	if request.signed != "" {
+		signed = request.signed
+	}
+
+	requestAt := strconv.FormatInt(request.requestAt.Unix(), 10)
+	httpRequest := httptest.NewRequest(request.method, request.path, strings.NewReader(request.body))
+	httpRequest.Header.Set(constants.XServiceName, request.service)
+	httpRequest.Header.Set(constants.XRequestAt, requestAt)
+	if request.nonce != "" {
+		httpRequest.Header.Set(constants.XRequestNonce, request.nonce)
+		httpRequest.Header.Set(constants.XApiKey, signature.Sign(request.key, request.service, request.method,
+			request.path, requestAt, request.nonce, signature.HashBody([]byte(signed))))
+	} else {
+		httpRequest.Header.Set(constants.XApiKey, signature.SignLegacy(request.key, request.service, requestAt))
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httpRequest)
+	return recorder
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	router := newSignatureRouter()
+
+	tests := []struct {
+		name    string
+		request signedRequest
+		status  int
+	}{
+		{name: "active key", request: signedRequest{service: "order-service", key: "active", body: `{"a":1}`}, status: http.StatusOK},
+		{name: "previous key", request: signedRequest{service: "order-service", key: "previous", body: `{"a":1}`}, status: http.StatusOK},
+		{name: "unknown key", request: signedRequest{service: "order-service", key: "other", body: `{"a":1}`}, status: http.StatusUnauthorized},
+		{name: "tampered body", request: signedRequest{service: "order-service", key: "active", body: `{"a":2}`, signed: `{"a":1}`}, status: http.StatusUnauthorized},
+		{name: "body over the limit", request: signedRequest{service: "order-service", key: "active", body: strings.Repeat("a", 2<<10)}, status: http.StatusUnauthorized},
+		{name: "request too old", request: signedRequest{service: "order-service", key: "active", requestAt: time.Now().Add(-10 * time.Minute)}, status: http.StatusUnauthorized},
+		{name: "request from the future", request: signedRequest{service: "order-service", key: "active", requestAt: time.Now().Add(10 * time.Minute)}, status: http.StatusUnauthorized},
+		{name: "unknown caller", request: signedRequest{service: "unknown-service", key: "active"}, status: http.StatusUnauthorized},
+		{name: "caller without keys", request: signedRequest{service: "retired-service", key: ""}, status: http.StatusUnauthorized},
+		{name: "route not allowed", request: signedRequest{service: "report-service", key: "report"}, status: http.StatusForbidden},
+		{name: "route not allowed with bad signature", request: signedRequest{service: "report-service", key: "other"}, status: http.StatusUnauthorized},
+		{name: "legacy signature", request: signedRequest{service: "order-service", key: "active", nonce: "-"}, status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := test.request
+			switch request.nonce {
+			case "":
+				request.nonce = uuid.NewString()
+			case "-":
+				request.nonce = ""
+			}
+
+			recorder := send(router, request)
+			if recorder.Code != test.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, test.status, recorder.Body.String())
+			}
+			if test.status == http.StatusOK && recorder.Body.String() != request.body {
+				t.Fatalf("handler read body %q, want %q", recorder.Body.String(), request.body)
+			}
+		})
+	}
+}
+
+func TestValidateAPIKeyNonceReplay(t *testing.T) {
+	router := newSignatureRouter()
+
+	tests := []struct {
+		name   string
+		second signedRequest
+		status int
+	}{
+		{name: "same request again", second: signedRequest{service: "order-service", key: "active"}, status: http.StatusUnauthorized},
+		{name: "same nonce with the previous key", second: signedRequest{service: "order-service", key: "previous"}, status: http.StatusUnauthorized},
+		{name: "same nonce on another method", second: signedRequest{service: "order-service", key: "active", method: http.MethodPut}, status: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nonce := uuid.NewString()
+			first := send(router, signedRequest{service: "order-service", key: "active", nonce: nonce})
+			if first.Code != http.StatusOK {
+				t.Fatalf("first status = %d, want %d", first.Code, http.StatusOK)
+			}
+
+			test.second.nonce = nonce
+			second := send(router, test.second)
+			if second.Code != test.status {
+				t.Fatalf("second status = %d, want %d", second.Code, test.status)
+			}
+		})
+	}
+
+	t.Run("rejected signature does not use up the nonce", func(t *testing.T) {
+		nonce := uuid.NewString()
+		rejected := send(router, signedRequest{service: "order-service", key: "other", nonce: nonce})
+		if rejected.Code != http.StatusUnauthorized {
+			t.Fatalf("rejected status = %d, want %d", rejected.Code, http.StatusUnauthorized)
+		}
+
+		accepted := send(router, signedRequest{service: "order-service", key: "active", nonce: nonce})
+		if accepted.Code != http.StatusOK {
+			t.Fatalf("accepted status = %d, want %d", accepted.Code, http.StatusOK)
+		}
+	})
+}
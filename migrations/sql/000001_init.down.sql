@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS field_schedules;
+DROP TABLE IF EXISTS times;
+DROP TABLE IF EXISTS fields;
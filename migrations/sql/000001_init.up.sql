@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS fields (
+    id             BIGSERIAL PRIMARY KEY,
+    uuid           UUID         NOT NULL,
+    venue_id       UUID,
+    code           VARCHAR(15)  NOT NULL,
+    name           VARCHAR(100) NOT NULL,
+    price_per_hour INT          NOT NULL,
+    images         TEXT[]       NOT NULL,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ
+);
+
+-- databases created by AutoMigrate before venues existed
+ALTER TABLE fields ADD COLUMN IF NOT EXISTS venue_id UUID;
+
+CREATE TABLE IF NOT EXISTS times (
+    id         BIGSERIAL PRIMARY KEY,
+    uuid       UUID NOT NULL,
+    start_time TIME WITHOUT TIME ZONE NOT NULL,
+    end_time   TIME WITHOUT TIME ZONE NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS field_schedules (
+    id         BIGSERIAL PRIMARY KEY,
+    uuid       UUID NOT NULL,
+    field_id   INT  NOT NULL,
+    time_id    INT  NOT NULL,
+    date       DATE NOT NULL,
+    status     INT  NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_fields_field_schedule FOREIGN KEY (field_id) REFERENCES fields (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_field_schedules_time FOREIGN KEY (time_id) REFERENCES times (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_fields_uuid ON fields (uuid);
+CREATE INDEX IF NOT EXISTS idx_times_uuid ON times (uuid);
+CREATE INDEX IF NOT EXISTS idx_field_schedules_uuid ON field_schedules (uuid);
+CREATE INDEX IF NOT EXISTS idx_field_schedules_field_id_date ON field_schedules (field_id, date);
@@ -0,0 +1,12 @@
+ALTER TABLE fields ADD COLUMN image_urls TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE fields
+SET image_urls = COALESCE(ARRAY(
+    SELECT image #>> '{sizes,full,url}'
+    FROM jsonb_array_elements(images) WITH ORDINALITY AS item(image, position)
+    WHERE image #>> '{sizes,full,url}' IS NOT NULL
+    ORDER BY position
+), '{}');
+
+ALTER TABLE fields DROP COLUMN images;
+ALTER TABLE fields RENAME COLUMN image_urls TO images;
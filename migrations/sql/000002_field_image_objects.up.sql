@@ -0,0 +1,11 @@
+-- images become structured objects; existing urls are kept as the full size.
+ALTER TABLE fields ADD COLUMN image_objects JSONB NOT NULL DEFAULT '[]';
+
+UPDATE fields
+SET image_objects = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('sizes', jsonb_build_object('full', jsonb_build_object('url', url))) ORDER BY position)
+    FROM unnest(images) WITH ORDINALITY AS image(url, position)
+), '[]');
+
+ALTER TABLE fields DROP COLUMN images;
+ALTER TABLE fields RENAME COLUMN image_objects TO images;
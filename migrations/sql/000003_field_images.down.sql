@@ -0,0 +1,16 @@
+ALTER TABLE fields ADD COLUMN images JSONB NOT NULL DEFAULT '[]';
+
+UPDATE fields
+SET images = COALESCE((
+    SELECT jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+        'hash', NULLIF(field_images.hash, ''),
+        'width', NULLIF(field_images.width, 0),
+        'height', NULLIF(field_images.height, 0),
+        'blurhash', NULLIF(field_images.blurhash, ''),
+        'sizes', field_images.sizes
+    )) ORDER BY field_images.position, field_images.id)
+    FROM field_images
+    WHERE field_images.field_id = fields.id
+), '[]');
+
+DROP TABLE IF EXISTS field_images;
@@ -0,0 +1,38 @@
+-- images move out of fields.images into their own table; the first image becomes the cover.
+CREATE TABLE IF NOT EXISTS field_images (
+    id         BIGSERIAL PRIMARY KEY,
+    uuid       UUID         NOT NULL,
+    field_id   INT          NOT NULL,
+    position   INT          NOT NULL,
+    alt_text   VARCHAR(255) NOT NULL DEFAULT '',
+    is_cover   BOOLEAN      NOT NULL DEFAULT FALSE,
+    hash       VARCHAR(64)  NOT NULL DEFAULT '',
+    width      INT          NOT NULL DEFAULT 0,
+    height     INT          NOT NULL DEFAULT 0,
+    blurhash   VARCHAR(64)  NOT NULL DEFAULT '',
+    sizes      JSONB        NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_fields_field_images FOREIGN KEY (field_id) REFERENCES fields (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_field_images_uuid ON field_images (uuid);
+CREATE INDEX IF NOT EXISTS idx_field_images_field_id_position ON field_images (field_id, position);
+CREATE INDEX IF NOT EXISTS idx_field_images_hash ON field_images (hash) WHERE hash <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_field_images_cover ON field_images (field_id) WHERE is_cover;
+
+INSERT INTO field_images (uuid, field_id, position, is_cover, hash, width, height, blurhash, sizes, created_at, updated_at)
+SELECT gen_random_uuid(),
+       fields.id,
+       item.position - 1,
+       item.position = 1,
+       COALESCE(item.image ->> 'hash', ''),
+       COALESCE((item.image ->> 'width')::INT, 0),
+       COALESCE((item.image ->> 'height')::INT, 0),
+       COALESCE(item.image ->> 'blurhash', ''),
+       COALESCE(item.image -> 'sizes', '{}'),
+       NOW(),
+       NOW()
+FROM fields, jsonb_array_elements(fields.images) WITH ORDINALITY AS item(image, position);
+
+ALTER TABLE fields DROP COLUMN images;
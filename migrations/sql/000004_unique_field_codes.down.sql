@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_fields_venue_id_code;
@@ -0,0 +1,23 @@
+-- field codes are unique per venue, ignoring case, among fields that are not deleted.
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(duplicate.code, ', ')
+    INTO duplicates
+    FROM (
+        SELECT LOWER(code) AS code
+        FROM fields
+        WHERE deleted_at IS NULL
+        GROUP BY venue_id, LOWER(code)
+        HAVING COUNT(*) > 1
+    ) AS duplicate;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'rename duplicate field codes before migrating: %', duplicates;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fields_venue_id_code
+    ON fields (COALESCE(venue_id, '00000000-0000-0000-0000-000000000000'), LOWER(code))
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- booked schedules keep the price they were booked at; existing bookings get the current price.
+ALTER TABLE field_schedules ADD COLUMN IF NOT EXISTS price_per_hour INT;
+
+UPDATE field_schedules
+SET price_per_hour = fields.price_per_hour
+FROM fields
+WHERE fields.id = field_schedules.field_id
+  AND field_schedules.status = 200;
@@ -0,0 +1,17 @@
+ALTER TABLE fields ADD COLUMN price_per_hour INT NOT NULL DEFAULT 0;
+
+-- keep the price effective today, or the first one for fields whose prices all start later.
+UPDATE fields
+SET price_per_hour = COALESCE((
+    SELECT field_prices.price_per_hour
+    FROM field_prices
+    WHERE field_prices.field_id = fields.id
+    ORDER BY field_prices.effective_from <= CURRENT_DATE DESC,
+             CASE WHEN field_prices.effective_from <= CURRENT_DATE THEN field_prices.effective_from END DESC,
+             field_prices.effective_from ASC
+    LIMIT 1
+), 0);
+
+ALTER TABLE fields ALTER COLUMN price_per_hour DROP DEFAULT;
+
+DROP TABLE IF EXISTS field_prices;
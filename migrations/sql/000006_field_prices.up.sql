@@ -0,0 +1,27 @@
+-- prices move out of fields.price_per_hour into a history; the current price applies from the day the field was created.
+CREATE TABLE IF NOT EXISTS field_prices (
+    id             BIGSERIAL PRIMARY KEY,
+    uuid           UUID NOT NULL,
+    field_id       INT  NOT NULL,
+    price_per_hour INT  NOT NULL,
+    effective_from DATE NOT NULL,
+    effective_to   DATE,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    CONSTRAINT fk_fields_field_prices FOREIGN KEY (field_id) REFERENCES fields (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT chk_field_prices_effective CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_field_prices_uuid ON field_prices (uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_field_prices_field_id_effective_from ON field_prices (field_id, effective_from);
+
+INSERT INTO field_prices (uuid, field_id, price_per_hour, effective_from, created_at, updated_at)
+SELECT gen_random_uuid(),
+       fields.id,
+       fields.price_per_hour,
+       COALESCE(fields.created_at::DATE, CURRENT_DATE),
+       NOW(),
+       NOW()
+FROM fields;
+
+ALTER TABLE fields DROP COLUMN price_per_hour;
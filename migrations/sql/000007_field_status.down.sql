@@ -0,0 +1,7 @@
+-- blocked schedules (300) become available again.
+UPDATE field_schedules SET status = 100 WHERE status = 300;
+
+DROP INDEX IF EXISTS idx_fields_status;
+ALTER TABLE fields DROP COLUMN IF EXISTS reopen_at;
+ALTER TABLE fields DROP COLUMN IF EXISTS maintenance_reason;
+ALTER TABLE fields DROP COLUMN IF EXISTS status;
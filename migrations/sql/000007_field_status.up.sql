@@ -0,0 +1,7 @@
+-- fields get a lifecycle status; existing fields stay visible as active (200).
+ALTER TABLE fields ADD COLUMN IF NOT EXISTS status INT NOT NULL DEFAULT 200;
+ALTER TABLE fields ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE fields ADD COLUMN IF NOT EXISTS maintenance_reason VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE fields ADD COLUMN IF NOT EXISTS reopen_at DATE;
+
+CREATE INDEX IF NOT EXISTS idx_fields_status ON fields (status);
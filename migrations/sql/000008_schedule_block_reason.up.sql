@@ -0,0 +1,5 @@
+-- blocked schedules remember why they were blocked, so reopening a field only
+-- releases the slots its status blocked. Every slot blocked so far was blocked by it.
+ALTER TABLE field_schedules ADD COLUMN IF NOT EXISTS block_reason VARCHAR(50) NOT NULL DEFAULT '';
+
+UPDATE field_schedules SET block_reason = 'field_status' WHERE status = 300;
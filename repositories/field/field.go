@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
+	"github.com/thomzes/field-service-booking-app/constants"
 	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
 	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
 	"github.com/thomzes/field-service-booking-app/domain/dto"
 	"github.com/thomzes/field-service-booking-app/domain/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FieldRepository struct {
@@ -20,17 +24,37 @@ type FieldRepository struct {
 
 type IFieldRepository interface {
 	FindAllWithPagination(context.Context, *dto.FieldRequestParam) ([]models.Field, int64, error)
-	FindAllWithoutPagination(context.Context) ([]models.Field, error)
+	FindAllWithoutPagination(context.Context, constants.FieldStatus) ([]models.Field, error)
 	FindByUUID(context.Context, string) (*models.Field, error)
+	FindAllByCode(context.Context, string) ([]models.Field, error)
 	Create(context.Context, *models.Field) (*models.Field, error)
 	Update(context.Context, string, *models.Field) (*models.Field, error)
-	Delete(context.Context, string) error
+	UpdateStatus(context.Context, string, *models.Field) error
+	Delete(context.Context, string, bool) ([]models.FieldSchedule, error)
+	FindAllTrashed(context.Context, *dto.TrashRequestParam) ([]models.Field, int64, error)
+	FindTrashedByUUID(context.Context, string) (*models.Field, error)
+	Restore(context.Context, string) (*models.Field, error)
+	Purge(context.Context, time.Time, *uuid.UUID) (int64, error)
 }
 
 func NewFieldRepository(db *gorm.DB) IFieldRepository {
 	return &FieldRepository{db: db}
 }
 
+// withImages loads the images of each field in display order.
+func withImages(db *gorm.DB) *gorm.DB {
+	return db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc, id asc")
+	})
+}
+
+// withPrices loads the price history of each field, oldest first.
+func withPrices(db *gorm.DB) *gorm.DB {
+	return db.Preload("Prices", func(db *gorm.DB) *gorm.DB {
+		return db.Order("effective_from asc")
+	})
+}
+
 func (f *FieldRepository) FindAllWithPagination(ctx context.Context, param *dto.FieldRequestParam) ([]models.Field, int64, error) {
 	var (
 		fields []models.Field
@@ -48,79 +72,268 @@ func (f *FieldRepository) FindAllWithPagination(ctx context.Context, param *dto.
 		sort = "created_at desc"
 	}
 
+	query := f.db.WithContext(ctx).Model(&models.Field{})
+	if param.Status != nil {
+		query = query.Where("status = ?", constants.FieldStatusName(*param.Status).GetStatusInt())
+	}
+	if len(param.Statuses) > 0 {
+		query = query.Where("status IN ?", param.Statuses)
+	}
+
 	limit := param.Limit
 	offset := (param.Page - 1) * limit
-	err := f.db.WithContext(ctx).Limit(limit).Offset(offset).Order(sort).Find(&fields).Error
+	err := query.Session(&gorm.Session{}).Scopes(withImages, withPrices).Limit(limit).Offset(offset).Order(sort).Find(&fields).Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
-	err = f.db.WithContext(ctx).Model(&fields).Count(&total).Error
+	err = query.Session(&gorm.Session{}).Count(&total).Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return fields, total, nil
 }
 
-func (f *FieldRepository) FindAllWithoutPagination(ctx context.Context) ([]models.Field, error) {
+func (f *FieldRepository) FindAllWithoutPagination(ctx context.Context, status constants.FieldStatus) ([]models.Field, error) {
 	var fields []models.Field
-	err := f.db.WithContext(ctx).Find(&fields).Error
+	err := f.db.WithContext(ctx).Scopes(withImages, withPrices).Where("status = ?", status).Find(&fields).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return fields, nil
 }
 
 func (f *FieldRepository) FindByUUID(ctx context.Context, uuid string) (*models.Field, error) {
 	var field models.Field
-	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Find(&field).Error
+	err := f.db.WithContext(ctx).Scopes(withImages, withPrices).Where("uuid = ?", uuid).First(&field).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errField.ErrFieldNotFound)
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return &field, nil
 }
 
+// FindAllByCode matches code ignoring case. Codes are only unique per venue,
+// so there can be several.
+func (f *FieldRepository) FindAllByCode(ctx context.Context, code string) ([]models.Field, error) {
+	var fields []models.Field
+	err := f.db.WithContext(ctx).Scopes(withImages, withPrices).Where("LOWER(code) = LOWER(?)", code).Find(&fields).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return fields, nil
+}
+
 func (f *FieldRepository) Create(ctx context.Context, req *models.Field) (*models.Field, error) {
 	field := models.Field{
-		UUID:         uuid.New(),
-		Code:         req.Code,
-		Name:         req.Name,
-		Images:       req.Images,
-		PricePerHour: req.PricePerHour,
+		UUID:    uuid.New(),
+		VenueID: req.VenueID,
+		Code:    req.Code,
+		Name:    req.Name,
+		Status:  req.Status,
+		Images:  req.Images,
+		Prices:  req.Prices,
 	}
 
 	err := f.db.WithContext(ctx).Create(&field).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldCodeExists)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 	return &field, nil
 }
 
 func (f *FieldRepository) Update(ctx context.Context, uuid string, req *models.Field) (*models.Field, error) {
 	field := models.Field{
-		Code:         req.Code,
-		Name:         req.Name,
-		Images:       req.Images,
-		PricePerHour: req.PricePerHour,
+		VenueID: req.VenueID,
+		Code:    req.Code,
+		Name:    req.Name,
 	}
 
 	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Updates(&field).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldCodeExists)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &field, nil
 }
 
-func (f *FieldRepository) Delete(ctx context.Context, uuid string) error {
-	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Delete(&models.Field{}).Error
-	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+// UpdateStatus sets the status of the field together with its maintenance
+// reason and reopen date, clearing them when empty.
+func (f *FieldRepository) UpdateStatus(ctx context.Context, uuid string, req *models.Field) error {
+	result := f.db.WithContext(ctx).Model(&models.Field{}).Where("uuid = ?", uuid).Updates(map[string]any{
+		"status":             req.Status,
+		"maintenance_reason": req.MaintenanceReason,
+		"reopen_at":          req.ReopenAt,
+	})
+	if result.Error != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	if result.RowsAffected == 0 {
+		return errWrap.WrapError(ctx, errField.ErrFieldNotFound)
 	}
 
 	return nil
 }
+
+// errHasBookings aborts Delete when the field has future bookings and the
+// delete is not forced.
+var errHasBookings = errors.New("field has future bookings")
+
+// Delete soft-deletes the field together with its live schedules. They share
+// the deletion time so Restore brings back exactly those schedules. Future
+// bookings refuse the delete unless force is set; then they are released and
+// returned so the caller can announce the cancellations.
+func (f *FieldRepository) Delete(ctx context.Context, uuid string, force bool) ([]models.FieldSchedule, error) {
+	var booked []models.FieldSchedule
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var field models.Field
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", uuid).First(&field).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Time").
+			Where("field_id = ?", field.ID).
+			Where("status = ?", constants.Booked).
+			Where("date >= ?", time.Now().Format(time.DateOnly)).
+			Find(&booked).Error
+		if err != nil {
+			return err
+		}
+
+		if len(booked) > 0 {
+			if !force {
+				return errHasBookings
+			}
+
+			ids := make([]uint, 0, len(booked))
+			for _, schedule := range booked {
+				ids = append(ids, schedule.ID)
+			}
+			err = tx.Model(&models.FieldSchedule{}).Where("id IN ?", ids).Updates(map[string]any{
+				"status":         constants.Available,
+				"price_per_hour": nil,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		deletedAt := time.Now()
+		err = tx.Model(&field).Update("deleted_at", deletedAt).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.FieldSchedule{}).Where("field_id = ?", field.ID).Update("deleted_at", deletedAt).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+		case errors.Is(err, errHasBookings):
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldHasBookings)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	metrics.SlotStatusChanges.WithLabelValues("released").Add(float64(len(booked)))
+
+	return booked, nil
+}
+
+func (f *FieldRepository) FindAllTrashed(ctx context.Context, param *dto.TrashRequestParam) ([]models.Field, int64, error) {
+	var (
+		fields []models.Field
+		total  int64
+	)
+
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	trashed := f.db.WithContext(ctx).Unscoped().Model(&models.Field{}).Where("deleted_at IS NOT NULL")
+	if param.VenueID != nil {
+		trashed = trashed.Where("venue_id = ?", *param.VenueID)
+	}
+	err := trashed.Session(&gorm.Session{}).Scopes(withImages, withPrices).Limit(limit).Offset(offset).Order("deleted_at desc").Find(&fields).Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	err = trashed.Session(&gorm.Session{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return fields, total, nil
+}
+
+func (f *FieldRepository) FindTrashedByUUID(ctx context.Context, uuid string) (*models.Field, error) {
+	var field models.Field
+	err := f.db.WithContext(ctx).Unscoped().Where("uuid = ?", uuid).Where("deleted_at IS NOT NULL").First(&field).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return &field, nil
+}
+
+// Restore undeletes a trashed field and the schedules deleted with it.
+func (f *FieldRepository) Restore(ctx context.Context, uuid string) (*models.Field, error) {
+	var field models.Field
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Scopes(withImages, withPrices).Where("uuid = ?", uuid).Where("deleted_at IS NOT NULL").First(&field).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&models.FieldSchedule{}).Where("field_id = ?", field.ID).
+			Where("deleted_at = ?", field.DeletedAt.Time).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&field).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		field.DeletedAt = nil
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldCodeExists)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return &field, nil
+}
+
+// Purge permanently deletes fields trashed before deletedBefore, only those of
+// venueID when it is set. Their images and schedules go with them through the
+// foreign keys.
+func (f *FieldRepository) Purge(ctx context.Context, deletedBefore time.Time, venueID *uuid.UUID) (int64, error) {
+	purged := f.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", deletedBefore)
+	if venueID != nil {
+		purged = purged.Where("venue_id = ?", *venueID)
+	}
+	result := purged.Delete(&models.Field{})
+	if result.Error != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return result.RowsAffected, nil
+}
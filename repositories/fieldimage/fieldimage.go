@@ -0,0 +1,295 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FieldImageRepository struct {
+	db *gorm.DB
+}
+
+type IFieldImageRepository interface {
+	FindAllByFieldID(context.Context, uint) ([]models.FieldImage, error)
+	FindByUUID(context.Context, uint, string) (*models.FieldImage, error)
+	FindAllReferenced(context.Context, time.Time) ([]models.FieldImage, error)
+	IsReferenced(context.Context, string, time.Time) (bool, error)
+	CountByHash(context.Context, string) (int64, error)
+	Create(context.Context, *models.FieldImage) (*models.FieldImage, error)
+	Replace(context.Context, uint, []models.FieldImage) ([]models.FieldImage, error)
+	UpdateAltText(context.Context, *models.FieldImage, string) error
+	Reorder(context.Context, uint, []uint) error
+	SetCover(context.Context, uint, uint) error
+	Delete(context.Context, *models.FieldImage) (bool, error)
+}
+
+func NewFieldImageRepository(db *gorm.DB) IFieldImageRepository {
+	return &FieldImageRepository{db: db}
+}
+
+// lockField serialises changes to the images of one field so positions and
+// the cover stay consistent.
+func lockField(tx *gorm.DB, fieldID uint) error {
+	var field models.Field
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", fieldID).First(&field).Error
+}
+
+func (f *FieldImageRepository) FindAllByFieldID(ctx context.Context, fieldID uint) ([]models.FieldImage, error) {
+	var images []models.FieldImage
+	err := f.db.WithContext(ctx).Where("field_id = ?", fieldID).Order("position asc, id asc").Find(&images).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return images, nil
+}
+
+func (f *FieldImageRepository) FindByUUID(ctx context.Context, fieldID uint, uuid string) (*models.FieldImage, error) {
+	var image models.FieldImage
+	err := f.db.WithContext(ctx).Where("field_id = ?", fieldID).Where("uuid = ?", uuid).First(&image).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldImageNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return &image, nil
+}
+
+// FindAllReferenced returns the sizes of every image whose field is live or was
+// deleted after deletedAfter.
+func (f *FieldImageRepository) FindAllReferenced(ctx context.Context, deletedAfter time.Time) ([]models.FieldImage, error) {
+	var images []models.FieldImage
+	err := f.db.WithContext(ctx).Select("field_images.sizes").
+		Joins("JOIN fields ON fields.id = field_images.field_id").
+		Where("fields.deleted_at IS NULL OR fields.deleted_at > ?", deletedAfter).
+		Find(&images).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return images, nil
+}
+
+// likeEscaper escapes the LIKE wildcards in a literal pattern part.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// IsReferenced reports whether an image whose field is live or was deleted
+// after deletedAfter has a size stored under key.
+func (f *FieldImageRepository) IsReferenced(ctx context.Context, key string, deletedAfter time.Time) (bool, error) {
+	var count int64
+	err := f.db.WithContext(ctx).Model(&models.FieldImage{}).
+		Joins("JOIN fields ON fields.id = field_images.field_id").
+		Where("fields.deleted_at IS NULL OR fields.deleted_at > ?", deletedAfter).
+		Where("field_images.sizes::text LIKE ?", `%/`+likeEscaper.Replace(key)+`"%`).
+		Count(&count).Error
+	if err != nil {
+		return false, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return count > 0, nil
+}
+
+// CountByHash counts the images that use the objects of hash.
+func (f *FieldImageRepository) CountByHash(ctx context.Context, hash string) (int64, error) {
+	var total int64
+	err := f.db.WithContext(ctx).Model(&models.FieldImage{}).Where("hash = ?", hash).Count(&total).Error
+	if err != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return total, nil
+}
+
+// Create appends req to the images of its field. The first image of a field
+// becomes its cover.
+func (f *FieldImageRepository) Create(ctx context.Context, req *models.FieldImage) (*models.FieldImage, error) {
+	image := *req
+	image.UUID = uuid.New()
+
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, image.FieldID)
+		if err != nil {
+			return err
+		}
+
+		var last struct {
+			Total    int64
+			Position int
+		}
+		err = tx.Model(&models.FieldImage{}).Select("COUNT(*) AS total, COALESCE(MAX(position), -1) AS position").
+			Where("field_id = ?", image.FieldID).Scan(&last).Error
+		if err != nil {
+			return err
+		}
+
+		image.Position = last.Position + 1
+		image.IsCover = last.Total == 0
+		return tx.Create(&image).Error
+	})
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return &image, nil
+}
+
+// unused returns the images among removed whose objects no remaining image
+// uses, once per hash. It runs under the field lock of the removal.
+func unused(tx *gorm.DB, removed []models.FieldImage) ([]models.FieldImage, error) {
+	var images []models.FieldImage
+	seen := make(map[string]struct{}, len(removed))
+	for _, image := range removed {
+		if image.Hash == "" {
+			images = append(images, image)
+			continue
+		}
+		if _, ok := seen[image.Hash]; ok {
+			continue
+		}
+		seen[image.Hash] = struct{}{}
+
+		var total int64
+		err := tx.Model(&models.FieldImage{}).Where("hash = ?", image.Hash).Count(&total).Error
+		if err != nil {
+			return nil, err
+		}
+		if total == 0 {
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// Replace swaps every image of a field for images and returns the removed
+// ones whose objects no image uses any more.
+func (f *FieldImageRepository) Replace(ctx context.Context, fieldID uint, images []models.FieldImage) ([]models.FieldImage, error) {
+	var orphaned []models.FieldImage
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, fieldID)
+		if err != nil {
+			return err
+		}
+
+		var removed []models.FieldImage
+		err = tx.Clauses(clause.Returning{}).Where("field_id = ?", fieldID).Delete(&removed).Error
+		if err != nil {
+			return err
+		}
+
+		if len(images) > 0 {
+			for i := range images {
+				images[i].FieldID = fieldID
+			}
+			err = tx.Create(&images).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		orphaned, err = unused(tx, removed)
+		return err
+	})
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return orphaned, nil
+}
+
+func (f *FieldImageRepository) UpdateAltText(ctx context.Context, image *models.FieldImage, altText string) error {
+	err := f.db.WithContext(ctx).Model(image).Update("alt_text", altText).Error
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+// Reorder sets the position of each image to its index in imageIDs.
+func (f *FieldImageRepository) Reorder(ctx context.Context, fieldID uint, imageIDs []uint) error {
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, fieldID)
+		if err != nil {
+			return err
+		}
+
+		for position, imageID := range imageIDs {
+			err = tx.Model(&models.FieldImage{}).Where("field_id = ?", fieldID).Where("id = ?", imageID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+func (f *FieldImageRepository) SetCover(ctx context.Context, fieldID uint, imageID uint) error {
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, fieldID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.FieldImage{}).Where("field_id = ?", fieldID).Where("is_cover").
+			Update("is_cover", false).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.FieldImage{}).Where("field_id = ?", fieldID).Where("id = ?", imageID).
+			Update("is_cover", true).Error
+	})
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return nil
+}
+
+// Delete removes image. When it was the cover, the first remaining image
+// takes over. It reports whether no image uses its objects any more.
+func (f *FieldImageRepository) Delete(ctx context.Context, image *models.FieldImage) (bool, error) {
+	orphaned := false
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, image.FieldID)
+		if err != nil {
+			return err
+		}
+
+		var deleted []models.FieldImage
+		err = tx.Clauses(clause.Returning{}).Where("id = ?", image.ID).Delete(&deleted).Error
+		if err != nil || len(deleted) == 0 {
+			return err
+		}
+
+		unusedImages, err := unused(tx, deleted)
+		if err != nil {
+			return err
+		}
+		orphaned = len(unusedImages) > 0
+		if !deleted[0].IsCover {
+			return nil
+		}
+
+		var next models.FieldImage
+		err = tx.Where("field_id = ?", image.FieldID).Order("position asc, id asc").Limit(1).Find(&next).Error
+		if err != nil || next.ID == 0 {
+			return err
+		}
+		return tx.Model(&next).Update("is_cover", true).Error
+	})
+	if err != nil {
+		return false, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FieldPriceRepository struct {
+	db *gorm.DB
+}
+
+type IFieldPriceRepository interface {
+	FindAllByFieldID(context.Context, uint) ([]models.FieldPrice, error)
+	FindByUUID(context.Context, uint, string) (*models.FieldPrice, error)
+	Set(context.Context, uint, int, time.Time) (*models.FieldPrice, error)
+	Delete(context.Context, *models.FieldPrice) error
+}
+
+func NewFieldPriceRepository(db *gorm.DB) IFieldPriceRepository {
+	return &FieldPriceRepository{db: db}
+}
+
+// lockField serialises price changes of one field so the effective ranges
+// stay contiguous.
+func lockField(tx *gorm.DB, fieldID uint) error {
+	var field models.Field
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", fieldID).First(&field).Error
+}
+
+// relink sets the end of every price of the field to the start of the next one.
+func relink(tx *gorm.DB, fieldID uint) error {
+	return tx.Exec(`UPDATE field_prices
+		SET effective_to = next.effective_from
+		FROM (
+			SELECT id, LEAD(effective_from) OVER (ORDER BY effective_from) AS effective_from
+			FROM field_prices
+			WHERE field_id = ?
+		) AS next
+		WHERE field_prices.id = next.id`, fieldID).Error
+}
+
+func (f *FieldPriceRepository) FindAllByFieldID(ctx context.Context, fieldID uint) ([]models.FieldPrice, error) {
+	var prices []models.FieldPrice
+	err := f.db.WithContext(ctx).Where("field_id = ?", fieldID).Order("effective_from asc").Find(&prices).Error
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return prices, nil
+}
+
+func (f *FieldPriceRepository) FindByUUID(ctx context.Context, fieldID uint, uuid string) (*models.FieldPrice, error) {
+	var price models.FieldPrice
+	err := f.db.WithContext(ctx).Where("field_id = ?", fieldID).Where("uuid = ?", uuid).First(&price).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldPriceNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return &price, nil
+}
+
+// Set makes pricePerHour effective from the day of effectiveFrom until the
+// next price change. A price already starting that day is replaced.
+func (f *FieldPriceRepository) Set(ctx context.Context, fieldID uint, pricePerHour int, effectiveFrom time.Time) (*models.FieldPrice, error) {
+	var price models.FieldPrice
+	day := effectiveFrom.Format(time.DateOnly)
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, fieldID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "field_id"}, {Name: "effective_from"}},
+			DoUpdates: clause.AssignmentColumns([]string{"price_per_hour", "updated_at"}),
+		}).Create(&models.FieldPrice{
+			UUID:          uuid.New(),
+			FieldID:       fieldID,
+			PricePerHour:  pricePerHour,
+			EffectiveFrom: effectiveFrom,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = relink(tx, fieldID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("field_id = ?", fieldID).Where("effective_from = ?", day).First(&price).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return &price, nil
+}
+
+// Delete removes a price; the previous one then lasts until the next change.
+func (f *FieldPriceRepository) Delete(ctx context.Context, price *models.FieldPrice) error {
+	err := f.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := lockField(tx, price.FieldID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Delete(price).Error
+		if err != nil {
+			return err
+		}
+
+		return relink(tx, price.FieldID)
+	})
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return nil
+}
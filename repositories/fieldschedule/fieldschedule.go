@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	errWrap "github.com/thomzes/field-service-booking-app/common/error"
 	"github.com/thomzes/field-service-booking-app/constants"
 	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
@@ -25,8 +27,15 @@ type IFieldScheduleRepository interface {
 	FindByDateAndTimeID(context.Context, string, int, int) (*models.FieldSchedule, error)
 	Create(context.Context, []models.FieldSchedule) error
 	Update(context.Context, string, *models.FieldSchedule) (*models.FieldSchedule, error)
-	UpdateStatus(context.Context, constants.FieldScheduleStatus, string) error
+	Book(context.Context, string, int) error
+	Block(context.Context, uint, time.Time, *time.Time) (int64, error)
+	Unblock(context.Context, uint, time.Time) (int64, error)
+	CountBooked(context.Context, uint, time.Time, *time.Time) (int64, error)
 	Delete(context.Context, string) error
+	FindAllTrashed(context.Context, *dto.TrashRequestParam) ([]models.FieldSchedule, int64, error)
+	FindTrashedByUUID(context.Context, string) (*models.FieldSchedule, error)
+	Restore(context.Context, string) error
+	Purge(context.Context, time.Time, *uuid.UUID) (int64, error)
 }
 
 func NewFieldScheduleRepository(db *gorm.DB) IFieldScheduleRepository {
@@ -48,14 +57,14 @@ func (f *FieldScheduleRepository) FindAllWithPagination(ctx context.Context, par
 
 	limit := param.Limit
 	offset := (param.Page - 1) * limit
-	err := f.db.WithContext(ctx).Preload("Field").Preload("Time").Limit(limit).Offset(offset).Order(sort).Find(&fieldSchedules).Error
+	err := f.db.WithContext(ctx).Preload("Field").Preload("Field.Prices", byEffectiveFrom).Preload("Time").Limit(limit).Offset(offset).Order(sort).Find(&fieldSchedules).Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	err = f.db.WithContext(ctx).Model(&fieldSchedules).Count(&total).Error
 	if err != nil {
-		return nil, 0, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return fieldSchedules, total, nil
@@ -64,9 +73,9 @@ func (f *FieldScheduleRepository) FindAllWithPagination(ctx context.Context, par
 func (f *FieldScheduleRepository) FindAllByFieldIDAndDate(ctx context.Context, fieldID int, date string) ([]models.FieldSchedule, error) {
 	var fieldSchedules []models.FieldSchedule
 
-	err := f.db.WithContext(ctx).Preload("Field").Preload("Time").Where("field_id = ?", fieldID).Where("date = ?", date).Joins("LEFT JOIN times ON field_schedules.time_id = times.id").Order("times.start_time asc").Find(&fieldSchedules).Error
+	err := f.db.WithContext(ctx).Preload("Field").Preload("Field.Prices", byEffectiveFrom).Preload("Time").Where("field_id = ?", fieldID).Where("date = ?", date).Joins("LEFT JOIN times ON field_schedules.time_id = times.id").Order("times.start_time asc").Find(&fieldSchedules).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return fieldSchedules, nil
@@ -74,12 +83,12 @@ func (f *FieldScheduleRepository) FindAllByFieldIDAndDate(ctx context.Context, f
 
 func (f *FieldScheduleRepository) FindByUUID(ctx context.Context, uuid string) (*models.FieldSchedule, error) {
 	var fieldSchedule models.FieldSchedule
-	err := f.db.WithContext(ctx).Preload("Field").Preload("Time").Where("uuid = ?", uuid).First(&fieldSchedule).Error
+	err := f.db.WithContext(ctx).Preload("Field").Preload("Field.Prices", byEffectiveFrom).Preload("Time").Where("uuid = ?", uuid).First(&fieldSchedule).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errFieldSchedule.ErrFieldScheduleNotFound)
+			return nil, errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleNotFound)
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &fieldSchedule, nil
@@ -92,7 +101,7 @@ func (f *FieldScheduleRepository) FindByDateAndTimeID(ctx context.Context, date
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &fieldSchedule, nil
@@ -101,7 +110,7 @@ func (f *FieldScheduleRepository) FindByDateAndTimeID(ctx context.Context, date
 func (f *FieldScheduleRepository) Create(ctx context.Context, req []models.FieldSchedule) error {
 	err := f.db.WithContext(ctx).Create(&req).Error
 	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return nil
@@ -116,32 +125,175 @@ func (f *FieldScheduleRepository) Update(ctx context.Context, uuid string, req *
 	fieldSchedule.Date = req.Date
 	err = f.db.WithContext(ctx).Save(&fieldSchedule).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return fieldSchedule, nil
 }
 
-func (f *FieldScheduleRepository) UpdateStatus(ctx context.Context, status constants.FieldScheduleStatus, uuid string) error {
-	fieldSchedule, err := f.FindByUUID(ctx, uuid)
-	if err != nil {
-		return err
+// Book marks an available schedule of an active field as booked and keeps the
+// price it was booked at. Only one of several concurrent bookings of the same
+// slot succeeds, and none succeeds once the field has left Active.
+func (f *FieldScheduleRepository) Book(ctx context.Context, uuid string, pricePerHour int) error {
+	result := f.db.WithContext(ctx).Model(&models.FieldSchedule{}).Where("field_schedules.uuid = ?", uuid).
+		Where("field_schedules.status = ?", constants.Available).
+		Where("EXISTS (SELECT 1 FROM fields WHERE fields.id = field_schedules.field_id AND fields.status = ? AND fields.deleted_at IS NULL)", constants.Active).
+		Updates(map[string]any{
+			"status":         constants.Booked,
+			"price_per_hour": pricePerHour,
+		})
+	if result.Error != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	if result.RowsAffected > 0 {
+		return nil
 	}
 
-	fieldSchedule.Status = status
-	err = f.db.WithContext(ctx).Save(&fieldSchedule).Error
+	var fieldSchedule models.FieldSchedule
+	err := f.db.WithContext(ctx).Select("status").Where("uuid = ?", uuid).First(&fieldSchedule).Error
 	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleNotFound)
+		}
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	if fieldSchedule.Status == constants.Booked {
+		return errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleBooked)
 	}
 
-	return nil
+	return errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleBlocked)
+}
+
+// between limits schedules of the field to dates from from on and, when until
+// is set, before until.
+func between(fieldID uint, from time.Time, until *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("field_id = ?", fieldID).Where("date >= ?", from.Format(time.DateOnly))
+		if until != nil {
+			db = db.Where("date < ?", until.Format(time.DateOnly))
+		}
+		return db
+	}
+}
+
+// Block makes the available schedules of the field between from and until
+// unbookable because of the field status.
+func (f *FieldScheduleRepository) Block(ctx context.Context, fieldID uint, from time.Time, until *time.Time) (int64, error) {
+	result := f.db.WithContext(ctx).Model(&models.FieldSchedule{}).Scopes(between(fieldID, from, until)).
+		Where("status = ?", constants.Available).Updates(map[string]any{
+		"status":       constants.Blocked,
+		"block_reason": constants.BlockReasonFieldStatus,
+	})
+	if result.Error != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return result.RowsAffected, nil
+}
+
+// Unblock makes the schedules of the field blocked by its status available from
+// from on. Slots blocked for other reasons stay blocked.
+func (f *FieldScheduleRepository) Unblock(ctx context.Context, fieldID uint, from time.Time) (int64, error) {
+	result := f.db.WithContext(ctx).Model(&models.FieldSchedule{}).Scopes(between(fieldID, from, nil)).
+		Where("status = ?", constants.Blocked).Where("block_reason = ?", constants.BlockReasonFieldStatus).
+		Updates(map[string]any{
+			"status":       constants.Available,
+			"block_reason": "",
+		})
+	if result.Error != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return result.RowsAffected, nil
+}
+
+func (f *FieldScheduleRepository) CountBooked(ctx context.Context, fieldID uint, from time.Time, until *time.Time) (int64, error) {
+	var total int64
+	err := f.db.WithContext(ctx).Model(&models.FieldSchedule{}).Scopes(between(fieldID, from, until)).
+		Where("status = ?", constants.Booked).Count(&total).Error
+	if err != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return total, nil
 }
 
 func (f *FieldScheduleRepository) Delete(ctx context.Context, uuid string) error {
 	err := f.db.WithContext(ctx).Where("uuid = ?", uuid).Delete(&models.FieldSchedule{}).Error
 	if err != nil {
-		return errWrap.WrapError(errConstant.ErrSQLError)
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return nil
 }
+
+func byEffectiveFrom(db *gorm.DB) *gorm.DB {
+	return db.Order("effective_from asc")
+}
+
+// withTrashedField loads the field even when it is in the trash as well.
+func withTrashedField(db *gorm.DB) *gorm.DB {
+	return db.Preload("Field", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Field.Prices", byEffectiveFrom).Preload("Time")
+}
+
+func (f *FieldScheduleRepository) FindAllTrashed(ctx context.Context, param *dto.TrashRequestParam) ([]models.FieldSchedule, int64, error) {
+	var (
+		fieldSchedules []models.FieldSchedule
+		total          int64
+	)
+
+	limit := param.Limit
+	offset := (param.Page - 1) * limit
+	trashed := f.db.WithContext(ctx).Unscoped().Model(&models.FieldSchedule{}).Where("deleted_at IS NOT NULL")
+	if param.VenueID != nil {
+		venueFields := f.db.Unscoped().Model(&models.Field{}).Select("id").Where("venue_id = ?", *param.VenueID)
+		trashed = trashed.Where("field_id IN (?)", venueFields)
+	}
+	err := trashed.Session(&gorm.Session{}).Scopes(withTrashedField).Limit(limit).Offset(offset).Order("deleted_at desc").Find(&fieldSchedules).Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	err = trashed.Session(&gorm.Session{}).Count(&total).Error
+	if err != nil {
+		return nil, 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return fieldSchedules, total, nil
+}
+
+func (f *FieldScheduleRepository) FindTrashedByUUID(ctx context.Context, uuid string) (*models.FieldSchedule, error) {
+	var fieldSchedule models.FieldSchedule
+	err := f.db.WithContext(ctx).Unscoped().Scopes(withTrashedField).Where("uuid = ?", uuid).Where("deleted_at IS NOT NULL").First(&fieldSchedule).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return &fieldSchedule, nil
+}
+
+func (f *FieldScheduleRepository) Restore(ctx context.Context, uuid string) error {
+	err := f.db.WithContext(ctx).Unscoped().Model(&models.FieldSchedule{}).Where("uuid = ?", uuid).Update("deleted_at", nil).Error
+	if err != nil {
+		return errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+
+	return nil
+}
+
+// Purge permanently deletes schedules trashed before deletedBefore, only those
+// of fields of venueID when it is set.
+func (f *FieldScheduleRepository) Purge(ctx context.Context, deletedBefore time.Time, venueID *uuid.UUID) (int64, error) {
+	purged := f.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", deletedBefore)
+	if venueID != nil {
+		venueFields := f.db.Unscoped().Model(&models.Field{}).Select("id").Where("venue_id = ?", *venueID)
+		purged = purged.Where("field_id IN (?)", venueFields)
+	}
+	result := purged.Delete(&models.FieldSchedule{})
+	if result.Error != nil {
+		return 0, errWrap.WrapError(ctx, errConstant.ErrSQLError)
+	}
+	return result.RowsAffected, nil
+}
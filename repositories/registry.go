@@ -1,7 +1,11 @@
 package repositories
 
 import (
+	"context"
+
 	fieldRepo "github.com/thomzes/field-service-booking-app/repositories/field"
+	fieldImageRepo "github.com/thomzes/field-service-booking-app/repositories/fieldimage"
+	fieldPriceRepo "github.com/thomzes/field-service-booking-app/repositories/fieldprice"
 	fieldScheduleRepo "github.com/thomzes/field-service-booking-app/repositories/fieldschedule"
 	timeScheduleRepo "github.com/thomzes/field-service-booking-app/repositories/time"
 	"gorm.io/gorm"
@@ -13,8 +17,11 @@ type Registry struct {
 
 type IRepositoryRegistry interface {
 	GetField() fieldRepo.IFieldRepository
+	GetFieldImage() fieldImageRepo.IFieldImageRepository
+	GetFieldPrice() fieldPriceRepo.IFieldPriceRepository
 	GetFieldSchedule() fieldScheduleRepo.IFieldScheduleRepository
 	GetTime() timeScheduleRepo.ITimeRepository
+	Transaction(context.Context, func(IRepositoryRegistry) error) error
 }
 
 func NewRepositoryRegistry(db *gorm.DB) IRepositoryRegistry {
@@ -25,6 +32,14 @@ func (r *Registry) GetField() fieldRepo.IFieldRepository {
 	return fieldRepo.NewFieldRepository(r.db)
 }
 
+func (r *Registry) GetFieldImage() fieldImageRepo.IFieldImageRepository {
+	return fieldImageRepo.NewFieldImageRepository(r.db)
+}
+
+func (r *Registry) GetFieldPrice() fieldPriceRepo.IFieldPriceRepository {
+	return fieldPriceRepo.NewFieldPriceRepository(r.db)
+}
+
 func (r *Registry) GetFieldSchedule() fieldScheduleRepo.IFieldScheduleRepository {
 	return fieldScheduleRepo.NewFieldScheduleRepository(r.db)
 }
@@ -32,3 +47,11 @@ func (r *Registry) GetFieldSchedule() fieldScheduleRepo.IFieldScheduleRepository
 func (r *Registry) GetTime() timeScheduleRepo.ITimeRepository {
 	return timeScheduleRepo.NewTimeRepository(r.db)
 }
+
+// Transaction runs fn with repositories bound to one database transaction. It
+// is committed when fn returns nil and rolled back otherwise.
+func (r *Registry) Transaction(ctx context.Context, fn func(IRepositoryRegistry) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Registry{db: tx})
+	})
+}
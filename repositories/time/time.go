@@ -32,7 +32,7 @@ func (t *TimeRepository) FindAll(ctx context.Context) ([]models.Time, error) {
 	var times []models.Time
 	err := t.db.WithContext(ctx).Find(&times).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return times, nil
@@ -43,9 +43,9 @@ func (t *TimeRepository) FindByUUID(ctx context.Context, uuid string) (*models.T
 	err := t.db.WithContext(ctx).Where("uuid = ?", uuid).First(&time).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errTime.ErrTimeNotFound)
+			return nil, errWrap.WrapError(ctx, errTime.ErrTimeNotFound)
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &time, nil
@@ -56,9 +56,9 @@ func (t *TimeRepository) FindByID(ctx context.Context, id string) (*models.Time,
 	err := t.db.WithContext(ctx).Where("id = ?", id).First(&time).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errWrap.WrapError(errTime.ErrTimeNotFound)
+			return nil, errWrap.WrapError(ctx, errTime.ErrTimeNotFound)
 		}
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &time, nil
@@ -72,7 +72,7 @@ func (t *TimeRepository) Create(ctx context.Context, req *models.Time) (*models.
 	}
 	err := t.db.WithContext(ctx).Create(&time).Error
 	if err != nil {
-		return nil, errWrap.WrapError(errConstant.ErrSQLError)
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSQLError)
 	}
 
 	return &time, err
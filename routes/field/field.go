@@ -30,22 +30,42 @@ func (f *FieldRoute) Run() {
 	group := f.group.Group("/field")
 	group.GET("", middlewares.AuthenticateWithoutToken(), f.controller.GetField().GetAllWithoutPagination)
 	group.GET(":uuid", middlewares.AuthenticateWithoutToken(), f.controller.GetField().GetByUUID)
+	group.GET("/code/:code", middlewares.AuthenticateWithoutToken(), f.controller.GetField().GetByCode)
 	group.Use(middlewares.Authenticate())
-	group.GET("/pagination", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, f.client),
+	group.GET("/pagination", middlewares.Authorize(constants.FieldRead, f.client),
 		f.controller.GetField().GetAllWithPagination)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
+	group.GET("/trash", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().GetTrash)
+	group.DELETE("/trash", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().PurgeTrash)
+	group.POST("/:uuid/restore", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().Restore)
+	group.POST("", middlewares.Authorize(constants.FieldWrite, f.client),
 		f.controller.GetField().Create)
-	group.PUT("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
+	group.PUT("/:uuid", middlewares.Authorize(constants.FieldWrite, f.client),
 		f.controller.GetField().Update)
-	group.DELETE("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, f.client),
+	group.DELETE("/:uuid", middlewares.Authorize(constants.FieldWrite, f.client),
 		f.controller.GetField().Delete)
+	group.PUT("/:uuid/status", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().UpdateStatus)
+	group.GET("/:uuid/prices", middlewares.Authorize(constants.FieldRead, f.client),
+		f.controller.GetField().GetPrices)
+	group.POST("/:uuid/prices", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().SchedulePrice)
+	group.DELETE("/:uuid/prices/:priceUUID", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().CancelPrice)
+	group.POST("/:uuid/images", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().AddImage)
+	group.POST("/:uuid/images/upload-url", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().CreateImageUploadURL)
+	group.POST("/:uuid/images/confirm", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().ConfirmImageUpload)
+	group.PUT("/:uuid/images/order", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().ReorderImages)
+	group.PATCH("/:uuid/images/:imageUUID", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().UpdateImage)
+	group.PUT("/:uuid/images/:imageUUID/cover", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().SetCoverImage)
+	group.DELETE("/:uuid/images/:imageUUID", middlewares.Authorize(constants.FieldWrite, f.client),
+		f.controller.GetField().DeleteImage)
 }
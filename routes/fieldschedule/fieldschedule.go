@@ -31,30 +31,20 @@ func (fs *FieldScheduleRoute) Run() {
 	group.GET("lists/:uuid", middlewares.AuthenticateWithoutToken(), fs.controller.GetFieldSchedule().GetAllByFieldIDAndDate)
 	group.PATCH("/status", middlewares.AuthenticateWithoutToken(), fs.controller.GetFieldSchedule().UpdateStatus)
 	group.Use(middlewares.Authenticate())
-	group.GET("/pagination", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, fs.client),
+	group.GET("/pagination", middlewares.Authorize(constants.ScheduleRead, fs.client),
 		fs.controller.GetFieldSchedule().GetAllWithPagination)
-	group.GET("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-		constants.Customer,
-	}, fs.client),
+	group.GET("/trash", middlewares.Authorize(constants.ScheduleWrite, fs.client),
+		fs.controller.GetFieldSchedule().GetTrash)
+	group.POST("/:uuid/restore", middlewares.Authorize(constants.ScheduleWrite, fs.client),
+		fs.controller.GetFieldSchedule().Restore)
+	group.GET("/:uuid", middlewares.Authorize(constants.ScheduleRead, fs.client),
 		fs.controller.GetFieldSchedule().GetByUUID)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, fs.client),
+	group.POST("", middlewares.Authorize(constants.ScheduleWrite, fs.client),
 		fs.controller.GetFieldSchedule().Create)
-	group.POST("/one-month", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, fs.client),
+	group.POST("/one-month", middlewares.Authorize(constants.ScheduleWrite, fs.client),
 		fs.controller.GetFieldSchedule().GenerateScheduleForOneMonth)
-	group.PUT("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, fs.client),
+	group.PUT("/:uuid", middlewares.Authorize(constants.ScheduleWrite, fs.client),
 		fs.controller.GetFieldSchedule().Update)
-	group.DELETE("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, fs.client),
+	group.DELETE("/:uuid", middlewares.Authorize(constants.ScheduleWrite, fs.client),
 		fs.controller.GetFieldSchedule().Delete)
 }
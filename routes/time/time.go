@@ -25,16 +25,10 @@ func NewTimeRoute(controller controllers.IControllerRegistry, group *gin.RouterG
 func (t *TimeRoute) Run() {
 	group := t.group.Group("/time")
 	group.Use(middlewares.Authenticate())
-	group.GET("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client),
+	group.GET("", middlewares.Authorize(constants.TimeRead, t.client),
 		t.controller.GetTime().GetAll)
-	group.GET("/:uuid", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client),
+	group.GET("/:uuid", middlewares.Authorize(constants.TimeRead, t.client),
 		t.controller.GetTime().GetByUUID)
-	group.POST("", middlewares.CheckRole([]string{
-		constants.Admin,
-	}, t.client),
+	group.POST("", middlewares.Authorize(constants.TimeWrite, t.client),
 		t.controller.GetTime().Create)
 }
@@ -0,0 +1,367 @@
+package seeders
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/thomzes/field-service-booking-app/common/imaging"
+	"github.com/thomzes/field-service-booking-app/constants"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+	"go.yaml.in/yaml/v3"
+	"gorm.io/gorm"
+)
+
+//go:embed fixtures/*.yaml
+var profiles embed.FS
+
+type TimeFixture struct {
+	StartTime string `json:"startTime" yaml:"startTime"`
+	EndTime   string `json:"endTime" yaml:"endTime"`
+}
+
+type FieldFixture struct {
+	Code         string   `json:"code" yaml:"code"`
+	Name         string   `json:"name" yaml:"name"`
+	PricePerHour int      `json:"pricePerHour" yaml:"pricePerHour"`
+	VenueID      string   `json:"venueID" yaml:"venueID"`
+	Images       []string `json:"images" yaml:"images"`
+}
+
+// ScheduleFixture creates Available slots for a field, found by its code and
+// venue like FieldFixture. StartDate defaults to tomorrow, Days to 1 and Times
+// to every configured time.
+type ScheduleFixture struct {
+	FieldCode string        `json:"fieldCode" yaml:"fieldCode"`
+	VenueID   string        `json:"venueID" yaml:"venueID"`
+	StartDate string        `json:"startDate" yaml:"startDate"`
+	Days      int           `json:"days" yaml:"days"`
+	Times     []TimeFixture `json:"times" yaml:"times"`
+}
+
+type Fixture struct {
+	Times     []TimeFixture     `json:"times" yaml:"times"`
+	Fields    []FieldFixture    `json:"fields" yaml:"fields"`
+	Schedules []ScheduleFixture `json:"schedules" yaml:"schedules"`
+}
+
+type Summary struct {
+	TimesCreated     int
+	FieldsCreated    int
+	FieldsUpdated    int
+	SchedulesCreated int
+}
+
+func LoadProfile(name string) (*Fixture, error) {
+	data, err := profiles.ReadFile(fmt.Sprintf("fixtures/%s.yaml", name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown seed profile %q", name)
+	}
+
+	return decode(data, ".yaml")
+}
+
+func LoadFile(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(data, filepath.Ext(path))
+}
+
+func decode(data []byte, ext string) (*Fixture, error) {
+	var fixture Fixture
+	var err error
+	switch strings.ToLower(ext) {
+	case ".json":
+		err = json.Unmarshal(data, &fixture)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fixture)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &fixture, nil
+}
+
+// Seed applies the fixture in one transaction. Times are keyed on their range,
+// fields on their code and venue, prices on their start, images on their URL
+// and schedules on field, date and time, so running it again only fills in
+// what is missing and leaves rows added through the API alone.
+func Seed(ctx context.Context, db *gorm.DB, fixture *Fixture) (*Summary, error) {
+	summary := &Summary{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range fixture.Times {
+			_, created, err := seedTime(tx, item)
+			if err != nil {
+				return err
+			}
+			if created {
+				summary.TimesCreated++
+			}
+		}
+
+		for _, item := range fixture.Fields {
+			created, err := seedField(tx, item)
+			if err != nil {
+				return err
+			}
+			if created {
+				summary.FieldsCreated++
+			} else {
+				summary.FieldsUpdated++
+			}
+		}
+
+		for _, item := range fixture.Schedules {
+			created, err := seedSchedules(tx, item)
+			if err != nil {
+				return err
+			}
+			summary.SchedulesCreated += created
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func seedTime(tx *gorm.DB, item TimeFixture) (*models.Time, bool, error) {
+	var scheduleTime models.Time
+	err := tx.Where("start_time = ? AND end_time = ?", item.StartTime, item.EndTime).First(&scheduleTime).Error
+	if err == nil {
+		return &scheduleTime, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	scheduleTime = models.Time{
+		UUID:      uuid.New(),
+		StartTime: item.StartTime,
+		EndTime:   item.EndTime,
+	}
+	err = tx.Create(&scheduleTime).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &scheduleTime, true, nil
+}
+
+func parseVenueID(code, raw string) (*uuid.UUID, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: invalid venueID: %w", code, err)
+	}
+	return &parsed, nil
+}
+
+// findField looks a field up the way codes are unique: ignoring case, within
+// its venue.
+func findField(tx *gorm.DB, code string, venueID *uuid.UUID) (*models.Field, error) {
+	var field models.Field
+	err := tx.Where("LOWER(code) = LOWER(?)", code).Where("venue_id IS NOT DISTINCT FROM ?", venueID).First(&field).Error
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+func seedField(tx *gorm.DB, item FieldFixture) (bool, error) {
+	venueID, err := parseVenueID(item.Code, item.VenueID)
+	if err != nil {
+		return false, err
+	}
+
+	field, err := findField(tx, item.Code, venueID)
+	if err == nil {
+		err = tx.Model(field).Update("name", item.Name).Error
+		if err != nil {
+			return false, err
+		}
+		err = upsertPrice(tx, *field, item.PricePerHour)
+		if err != nil {
+			return false, err
+		}
+		return false, upsertImages(tx, field.ID, item.Images)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	images := make([]models.FieldImage, 0, len(item.Images))
+	for i, url := range item.Images {
+		images = append(images, fixtureImage(url, i, i == 0))
+	}
+
+	return true, tx.Create(&models.Field{
+		UUID:    uuid.New(),
+		VenueID: venueID,
+		Code:    item.Code,
+		Name:    item.Name,
+		Status:  constants.Active,
+		Images:  images,
+		Prices: []models.FieldPrice{{
+			UUID:          uuid.New(),
+			PricePerHour:  item.PricePerHour,
+			EffectiveFrom: dateOf(time.Now()),
+		}},
+	}).Error
+}
+
+// upsertPrice sets pricePerHour on the price the seed created, the one
+// effective since the field was created. Later prices are left as they are.
+func upsertPrice(tx *gorm.DB, field models.Field, pricePerHour int) error {
+	effectiveFrom := dateOf(time.Now())
+	if field.CreatedAt != nil {
+		effectiveFrom = dateOf(*field.CreatedAt)
+	}
+
+	result := tx.Model(&models.FieldPrice{}).Where("field_id = ?", field.ID).
+		Where("effective_from = ?", effectiveFrom.Format(time.DateOnly)).Update("price_per_hour", pricePerHour)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	return tx.Create(&models.FieldPrice{
+		UUID:          uuid.New(),
+		FieldID:       field.ID,
+		PricePerHour:  pricePerHour,
+		EffectiveFrom: effectiveFrom,
+	}).Error
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func fixtureImage(url string, position int, isCover bool) models.FieldImage {
+	return models.FieldImage{
+		UUID:     uuid.New(),
+		Position: position,
+		IsCover:  isCover,
+		Sizes:    models.ImageSizes{imaging.SizeFull: {URL: url}},
+	}
+}
+
+// upsertImages appends the images of urls the field does not have yet. Images
+// are matched on their full size URL; the others are kept.
+func upsertImages(tx *gorm.DB, fieldID uint, urls []string) error {
+	var existing []models.FieldImage
+	err := tx.Where("field_id = ?", fieldID).Order("position asc, id asc").Find(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(existing))
+	position := 0
+	for _, image := range existing {
+		known[image.Sizes[imaging.SizeFull].URL] = struct{}{}
+		position = max(position, image.Position+1)
+	}
+
+	for _, url := range urls {
+		if _, ok := known[url]; ok {
+			continue
+		}
+		known[url] = struct{}{}
+
+		image := fixtureImage(url, position, len(existing) == 0 && position == 0)
+		image.FieldID = fieldID
+		err = tx.Create(&image).Error
+		if err != nil {
+			return err
+		}
+		position++
+	}
+	return nil
+}
+
+func seedSchedules(tx *gorm.DB, item ScheduleFixture) (int, error) {
+	venueID, err := parseVenueID(item.FieldCode, item.VenueID)
+	if err != nil {
+		return 0, err
+	}
+
+	field, err := findField(tx, item.FieldCode, venueID)
+	if err != nil {
+		return 0, fmt.Errorf("schedule for field %s: %w", item.FieldCode, err)
+	}
+
+	var times []models.Time
+	if len(item.Times) == 0 {
+		err = tx.Order("start_time asc").Find(&times).Error
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		for _, timeFixture := range item.Times {
+			scheduleTime, _, err := seedTime(tx, timeFixture)
+			if err != nil {
+				return 0, err
+			}
+			times = append(times, *scheduleTime)
+		}
+	}
+
+	startDate := time.Now().AddDate(0, 0, 1)
+	if item.StartDate != "" {
+		startDate, err = time.Parse(time.DateOnly, item.StartDate)
+		if err != nil {
+			return 0, fmt.Errorf("schedule for field %s: invalid startDate: %w", item.FieldCode, err)
+		}
+	}
+
+	days := max(item.Days, 1)
+	created := 0
+	for i := 0; i < days; i++ {
+		date := startDate.AddDate(0, 0, i)
+		for _, scheduleTime := range times {
+			var count int64
+			err = tx.Model(&models.FieldSchedule{}).
+				Where("field_id = ? AND time_id = ? AND date = ?", field.ID, scheduleTime.ID, date.Format(time.DateOnly)).
+				Count(&count).Error
+			if err != nil {
+				return 0, err
+			}
+			if count > 0 {
+				continue
+			}
+
+			err = tx.Create(&models.FieldSchedule{
+				UUID:    uuid.New(),
+				FieldID: field.ID,
+				TimeID:  scheduleTime.ID,
+				Date:    date,
+				Status:  constants.Available,
+			}).Error
+			if err != nil {
+				return 0, err
+			}
+			created++
+		}
+	}
+
+	logrus.Infof("seeded %d schedules for field %s", created, field.Code)
+	return created, nil
+}
@@ -1,18 +1,20 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"path"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/thomzes/field-service-booking-app/common/gcs"
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/events"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/policy"
+	"github.com/thomzes/field-service-booking-app/common/storage"
 	"github.com/thomzes/field-service-booking-app/common/util"
-	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
 	"github.com/thomzes/field-service-booking-app/domain/dto"
 	"github.com/thomzes/field-service-booking-app/domain/models"
 	"github.com/thomzes/field-service-booking-app/repositories"
@@ -20,23 +22,40 @@ import (
 
 type FieldService struct {
 	repository repositories.IRepositoryRegistry
-	gcs        gcs.IGCSClient
+	storage    storage.IStorage
+	publisher  events.IPublisher
 }
 
 type IFieldService interface {
 	GetAllWithPagination(context.Context, *dto.FieldRequestParam) (*util.PaginationResult, error)
 	GetAllWithoutPagination(context.Context) ([]dto.FieldResponse, error)
 	GetByUUID(context.Context, string) (*dto.FieldResponse, error)
+	GetByCode(context.Context, string, *dto.FieldCodeRequestParam) (*dto.FieldResponse, error)
 	Create(context.Context, *dto.FieldRequest) (*dto.FieldResponse, error)
 	Update(context.Context, string, *dto.UpdateFieldRequest) (*dto.FieldResponse, error)
-	Delete(context.Context, string) error
+	Delete(context.Context, string, *dto.DeleteFieldRequestParam) error
+	AddImage(context.Context, string, *dto.FieldImageRequest) (*dto.FieldImageResponse, error)
+	UpdateImage(context.Context, string, string, *dto.UpdateFieldImageRequest) (*dto.FieldImageResponse, error)
+	DeleteImage(context.Context, string, string) error
+	ReorderImages(context.Context, string, *dto.ReorderFieldImagesRequest) ([]dto.FieldImageResponse, error)
+	SetCoverImage(context.Context, string, string) ([]dto.FieldImageResponse, error)
+	GetTrash(context.Context, *dto.TrashRequestParam) (*util.PaginationResult, error)
+	Restore(context.Context, string) (*dto.FieldResponse, error)
+	PurgeTrash(context.Context) (*dto.PurgeTrashResponse, error)
+	GetPrices(context.Context, string, *dto.FieldPriceRequestParam) ([]dto.FieldPriceResponse, error)
+	SchedulePrice(context.Context, string, *dto.FieldPriceRequest) (*dto.FieldPriceResponse, error)
+	CancelPrice(context.Context, string, string) error
+	UpdateStatus(context.Context, string, *dto.UpdateFieldStatusRequest) (*dto.FieldStatusResponse, error)
+	CreateImageUploadURL(context.Context, string, *dto.FieldImageUploadURLRequest) (*dto.FieldImageUploadURLResponse, error)
+	ConfirmImageUpload(context.Context, string, *dto.ConfirmFieldImageUploadRequest) (*dto.FieldImageResponse, error)
 }
 
-func NewFieldService(repository repositories.IRepositoryRegistry, gcs gcs.IGCSClient) IFieldService {
-	return &FieldService{repository: repository, gcs: gcs}
+func NewFieldService(repository repositories.IRepositoryRegistry, storage storage.IStorage, publisher events.IPublisher) IFieldService {
+	return &FieldService{repository: repository, storage: storage, publisher: publisher}
 }
 
 func (f *FieldService) GetAllWithPagination(ctx context.Context, param *dto.FieldRequestParam) (*util.PaginationResult, error) {
+	param.Statuses = visibleStatuses(ctx)
 	fields, total, err := f.repository.GetField().FindAllWithPagination(ctx, param)
 	if err != nil {
 		return nil, err
@@ -45,13 +64,17 @@ func (f *FieldService) GetAllWithPagination(ctx context.Context, param *dto.Fiel
 	fieldResults := make([]dto.FieldResponse, 0, len(fields))
 	for _, field := range fields {
 		fieldResults = append(fieldResults, dto.FieldResponse{
-			UUID:         field.UUID,
-			Code:         field.Code,
-			Name:         field.Name,
-			PricePerHour: field.PricePerHour,
-			Images:       field.Images,
-			CreatedAt:    field.CreatedAt,
-			UpdatedAt:    field.UpdatedAt,
+			UUID:              field.UUID,
+			VenueID:           field.VenueID,
+			Code:              field.Code,
+			Name:              field.Name,
+			PricePerHour:      field.PriceOn(time.Now()),
+			Status:            field.Status.GetStatusString(),
+			MaintenanceReason: field.MaintenanceReason,
+			ReopenAt:          formatDate(field.ReopenAt),
+			Images:            toImageResponses(field.Images),
+			CreatedAt:         field.CreatedAt,
+			UpdatedAt:         field.UpdatedAt,
 		})
 	}
 
@@ -68,7 +91,7 @@ func (f *FieldService) GetAllWithPagination(ctx context.Context, param *dto.Fiel
 }
 
 func (f *FieldService) GetAllWithoutPagination(ctx context.Context) ([]dto.FieldResponse, error) {
-	fields, err := f.repository.GetField().FindAllWithoutPagination(ctx)
+	fields, err := f.repository.GetField().FindAllWithoutPagination(ctx, constants.Active)
 	if err != nil {
 		return nil, err
 	}
@@ -76,116 +99,181 @@ func (f *FieldService) GetAllWithoutPagination(ctx context.Context) ([]dto.Field
 	fieldResults := make([]dto.FieldResponse, 0, len(fields))
 	for _, field := range fields {
 		fieldResults = append(fieldResults, dto.FieldResponse{
-			UUID:         field.UUID,
-			Name:         field.Name,
-			Code:         field.Code,
-			PricePerHour: field.PricePerHour,
-			Images:       field.Images,
-			CreatedAt:    field.CreatedAt,
-			UpdatedAt:    field.UpdatedAt,
+			UUID:              field.UUID,
+			VenueID:           field.VenueID,
+			Name:              field.Name,
+			Code:              field.Code,
+			PricePerHour:      field.PriceOn(time.Now()),
+			Status:            field.Status.GetStatusString(),
+			MaintenanceReason: field.MaintenanceReason,
+			ReopenAt:          formatDate(field.ReopenAt),
+			Images:            toImageResponses(field.Images),
+			CreatedAt:         field.CreatedAt,
+			UpdatedAt:         field.UpdatedAt,
 		})
 	}
 
 	return fieldResults, nil
 }
 
+// GetByUUID returns a field the caller may see.
 func (f *FieldService) GetByUUID(ctx context.Context, uuid string) (*dto.FieldResponse, error) {
 	field, err := f.repository.GetField().FindByUUID(ctx, uuid)
 	if err != nil {
 		return nil, err
 	}
 
+	if !isVisible(ctx, field.Status) {
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+	}
+
 	fieldResult := dto.FieldResponse{
-		UUID:         field.UUID,
-		Code:         field.Code,
-		Name:         field.Name,
-		PricePerHour: field.PricePerHour,
-		Images:       field.Images,
-		CreatedAt:    field.CreatedAt,
-		UpdatedAt:    field.UpdatedAt,
+		UUID:              field.UUID,
+		VenueID:           field.VenueID,
+		Code:              field.Code,
+		Name:              field.Name,
+		PricePerHour:      field.PriceOn(time.Now()),
+		Status:            field.Status.GetStatusString(),
+		MaintenanceReason: field.MaintenanceReason,
+		ReopenAt:          formatDate(field.ReopenAt),
+		Images:            toImageResponses(field.Images),
+		CreatedAt:         field.CreatedAt,
+		UpdatedAt:         field.UpdatedAt,
 	}
 
 	return &fieldResult, err
 }
 
-func (f *FieldService) validateUpload(images []multipart.FileHeader) error {
-	if images == nil || len(images) == 0 {
-		return errConstant.ErrInvalidUploadFile
+// GetByCode finds a field by its code, ignoring case and the fields the caller
+// may not see. When the code is used in several venues the venue has to be
+// given.
+func (f *FieldService) GetByCode(ctx context.Context, code string, param *dto.FieldCodeRequestParam) (*dto.FieldResponse, error) {
+	fields, err := f.repository.GetField().FindAllByCode(ctx, code)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, image := range images {
-		if image.Size > 5*1024*1024 {
-			return errConstant.ErrSizeTooBig
+	venueID := parseVenueID(param.VenueID)
+	matches := make([]models.Field, 0, 1)
+	for _, field := range fields {
+		if !isVisible(ctx, field.Status) {
+			continue
+		}
+		if param.VenueID == "" || sameVenue(field.VenueID, venueID) {
+			matches = append(matches, field)
 		}
 	}
+	fields = matches
 
-	return nil
+	switch {
+	case len(fields) == 0:
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+	case len(fields) > 1:
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldCodeAmbiguous)
+	}
+
+	field := fields[0]
+	fieldResult := dto.FieldResponse{
+		UUID:              field.UUID,
+		VenueID:           field.VenueID,
+		Code:              field.Code,
+		Name:              field.Name,
+		PricePerHour:      field.PriceOn(time.Now()),
+		Status:            field.Status.GetStatusString(),
+		MaintenanceReason: field.MaintenanceReason,
+		ReopenAt:          formatDate(field.ReopenAt),
+		Images:            toImageResponses(field.Images),
+		CreatedAt:         field.CreatedAt,
+		UpdatedAt:         field.UpdatedAt,
+	}
+
+	return &fieldResult, nil
 }
 
-func (f *FieldService) processAndUploadImage(ctx context.Context, image multipart.FileHeader) (string, error) {
-	file, err := image.Open()
+// checkCodeAvailable returns ErrFieldCodeExists when another field of the
+// venue already uses code. The unique index catches concurrent writes.
+func (f *FieldService) checkCodeAvailable(ctx context.Context, code string, venueID *uuid.UUID, fieldID uint) error {
+	fields, err := f.repository.GetField().FindAllByCode(ctx, code)
 	if err != nil {
-		return "", nil
+		return err
 	}
-	defer file.Close()
 
-	buffer := new(bytes.Buffer)
-	_, err = io.Copy(buffer, file)
-	if err != nil {
-		return "", nil
+	for _, field := range fields {
+		if field.ID != fieldID && sameVenue(field.VenueID, venueID) {
+			return errWrap.WrapError(ctx, errField.ErrFieldCodeExists)
+		}
 	}
+	return nil
+}
 
-	filename := fmt.Sprintf("images/%s-%s-%s", time.Now().Format("20060102150405"), image.Filename, path.Ext(image.Filename))
-	url, err := f.gcs.UploadFile(ctx, filename, buffer.Bytes())
-	if err != nil {
-		return "", nil
+func sameVenue(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
+	return *a == *b
+}
 
-	return url, nil
+func formatDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format(time.DateOnly)
+	return &formatted
 }
 
-func (f *FieldService) uploadImage(ctx context.Context, images []multipart.FileHeader) ([]string, error) {
-	err := f.validateUpload(images)
+func parseVenueID(venueID string) *uuid.UUID {
+	parsed, err := uuid.Parse(venueID)
 	if err != nil {
-		return nil, err
+		return nil
 	}
+	return &parsed
+}
 
-	urls := make([]string, 0, len(images))
-	for _, image := range images {
-		url, err := f.processAndUploadImage(ctx, image)
-		if err != nil {
-			return nil, err
-		}
-		urls = append(urls, url)
+func (f *FieldService) Create(ctx context.Context, request *dto.FieldRequest) (*dto.FieldResponse, error) {
+	err := policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: parseVenueID(request.VenueID)})
+	if err != nil {
+		return nil, err
 	}
 
-	return urls, nil
-}
+	err = f.checkCodeAvailable(ctx, request.Code, parseVenueID(request.VenueID), 0)
+	if err != nil {
+		return nil, err
+	}
 
-func (f *FieldService) Create(ctx context.Context, request *dto.FieldRequest) (*dto.FieldResponse, error) {
-	imageUrl, err := f.uploadImage(ctx, request.Images)
+	images, err := f.uploadImage(ctx, request.Images)
 	if err != nil {
 		return nil, err
 	}
 
 	field, err := f.repository.GetField().Create(ctx, &models.Field{
-		Code:         request.Code,
-		Name:         request.Name,
-		PricePerHour: request.PricePerHour,
-		Images:       imageUrl,
+		VenueID: parseVenueID(request.VenueID),
+		Code:    request.Code,
+		Name:    request.Name,
+		Status:  createStatus(request.Status),
+		Images:  images,
+		Prices: []models.FieldPrice{{
+			UUID:          uuid.New(),
+			PricePerHour:  request.PricePerHour,
+			EffectiveFrom: today(),
+		}},
 	})
 	if err != nil {
+		f.rollbackUploads(ctx, images)
 		return nil, err
 	}
 
 	response := &dto.FieldResponse{
-		UUID:         field.UUID,
-		Code:         field.Code,
-		Name:         field.Name,
-		PricePerHour: field.PricePerHour,
-		Images:       field.Images,
-		CreatedAt:    field.CreatedAt,
-		UpdatedAt:    field.UpdatedAt,
+		UUID:              field.UUID,
+		VenueID:           field.VenueID,
+		Code:              field.Code,
+		Name:              field.Name,
+		PricePerHour:      field.PriceOn(time.Now()),
+		Status:            field.Status.GetStatusString(),
+		MaintenanceReason: field.MaintenanceReason,
+		ReopenAt:          formatDate(field.ReopenAt),
+		Images:            toImageResponses(field.Images),
+		CreatedAt:         field.CreatedAt,
+		UpdatedAt:         field.UpdatedAt,
 	}
 
 	return response, nil
@@ -197,51 +285,253 @@ func (f *FieldService) Update(ctx context.Context, uuidParam string, req *dto.Up
 		return nil, err
 	}
 
-	var imageUrl []string
-	if req.Images == nil {
-		imageUrl = field.Images
-	} else {
-		imageUrl, err = f.uploadImage(ctx, req.Images)
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty venueID leaves the venue unchanged
+	venueID := parseVenueID(req.VenueID)
+	if venueID == nil {
+		venueID = field.VenueID
+	}
+	// moving a field needs the permission on the new venue as well
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: venueID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.checkCodeAvailable(ctx, req.Code, venueID, field.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := field.Images
+	if req.Images != nil {
+		images, err = f.uploadImage(ctx, req.Images)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	fieldResult, err := f.repository.GetField().Update(ctx, uuidParam, &models.Field{
-		Code:         req.Code,
-		Name:         req.Name,
-		PricePerHour: req.PricePerHour,
-		Images:       imageUrl,
+	// the field, its price and its images change together or not at all
+	var (
+		fieldResult *models.Field
+		orphaned    []models.FieldImage
+	)
+	err = f.repository.Transaction(ctx, func(repository repositories.IRepositoryRegistry) error {
+		var err error
+		fieldResult, err = repository.GetField().Update(ctx, uuidParam, &models.Field{
+			VenueID: parseVenueID(req.VenueID),
+			Code:    req.Code,
+			Name:    req.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		// a new price applies from today, scheduled changes still follow
+		if req.PricePerHour != field.PriceOn(time.Now()) {
+			_, err = repository.GetFieldPrice().Set(ctx, field.ID, req.PricePerHour, today())
+			if err != nil {
+				return err
+			}
+		}
+
+		if req.Images != nil {
+			orphaned, err = repository.GetFieldImage().Replace(ctx, field.ID, images)
+		}
+		return err
 	})
 	if err != nil {
+		if req.Images != nil {
+			f.rollbackUploads(ctx, images)
+		}
 		return nil, err
 	}
+	f.deleteImageObjects(ctx, orphaned)
 
 	uuidParsed, _ := uuid.Parse(uuidParam)
 	response := dto.FieldResponse{
-		UUID:         uuidParsed,
-		Code:         fieldResult.Code,
-		Name:         fieldResult.Name,
-		PricePerHour: fieldResult.PricePerHour,
-		Images:       fieldResult.Images,
-		CreatedAt:    fieldResult.CreatedAt,
-		UpdatedAt:    fieldResult.UpdatedAt,
+		UUID:              uuidParsed,
+		VenueID:           fieldResult.VenueID,
+		Code:              fieldResult.Code,
+		Name:              fieldResult.Name,
+		PricePerHour:      req.PricePerHour,
+		Status:            field.Status.GetStatusString(),
+		MaintenanceReason: field.MaintenanceReason,
+		ReopenAt:          formatDate(field.ReopenAt),
+		Images:            toImageResponses(images),
+		CreatedAt:         fieldResult.CreatedAt,
+		UpdatedAt:         fieldResult.UpdatedAt,
 	}
 
 	return &response, nil
 
 }
 
-func (f *FieldService) Delete(ctx context.Context, uuid string) error {
-	_, err := f.repository.GetField().FindByUUID(ctx, uuid)
+// Delete moves the field to the trash. Future bookings refuse the delete
+// unless it is forced, in which case they are released and a cancellation is
+// published for each of them.
+func (f *FieldService) Delete(ctx context.Context, uuid string, param *dto.DeleteFieldRequestParam) error {
+	field, err := f.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
 	if err != nil {
 		return err
 	}
 
-	err = f.repository.GetField().Delete(ctx, uuid)
+	cancelled, err := f.repository.GetField().Delete(ctx, uuid, param.Force)
 	if err != nil {
 		return err
 	}
 
+	deleteEvents := make([]events.Event, 0, len(cancelled)+1)
+	for _, schedule := range cancelled {
+		deleteEvents = append(deleteEvents, events.New(events.TypeBookingCancelled, dto.BookingCancelledEvent{
+			FieldUUID:    field.UUID,
+			ScheduleUUID: schedule.UUID,
+			Date:         schedule.Date.Format(time.DateOnly),
+			Time:         fmt.Sprintf("%s-%s", schedule.Time.StartTime, schedule.Time.EndTime),
+			PricePerHour: bookedPrice(schedule, field.PriceOn(schedule.Date)),
+			Reason:       events.TypeFieldDeleted,
+		}))
+	}
+	deleteEvents = append(deleteEvents, events.New(events.TypeFieldDeleted, dto.FieldDeletedEvent{
+		FieldUUID:          field.UUID,
+		Code:               field.Code,
+		Forced:             param.Force,
+		CancelledSchedules: len(cancelled),
+	}))
+
+	// the field is already deleted, so a failed publish is only logged
+	err = f.publisher.Publish(context.WithoutCancel(ctx), deleteEvents...)
+	if err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("field_uuid", field.UUID).
+			Error("failed to publish field delete events")
+	}
+
 	return nil
 }
+
+func bookedPrice(schedule models.FieldSchedule, fallback int) int {
+	if schedule.PricePerHour != nil {
+		return *schedule.PricePerHour
+	}
+	return fallback
+}
+
+func (f *FieldService) GetTrash(ctx context.Context, param *dto.TrashRequestParam) (*util.PaginationResult, error) {
+	venueID, err := policy.VenueFilter(ctx, constants.FieldWrite)
+	if err != nil {
+		return nil, err
+	}
+	param.VenueID = venueID
+
+	fields, total, err := f.repository.GetField().FindAllTrashed(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldResults := make([]dto.FieldResponse, 0, len(fields))
+	for _, field := range fields {
+		fieldResults = append(fieldResults, dto.FieldResponse{
+			UUID:              field.UUID,
+			VenueID:           field.VenueID,
+			Code:              field.Code,
+			Name:              field.Name,
+			PricePerHour:      field.PriceOn(time.Now()),
+			Status:            field.Status.GetStatusString(),
+			MaintenanceReason: field.MaintenanceReason,
+			ReopenAt:          formatDate(field.ReopenAt),
+			Images:            toImageResponses(field.Images),
+			CreatedAt:         field.CreatedAt,
+			UpdatedAt:         field.UpdatedAt,
+			DeletedAt:         util.DeletedAt(field.DeletedAt),
+		})
+	}
+
+	response := util.GeneratePagination(util.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  fieldResults,
+	})
+
+	return &response, nil
+}
+
+// trashedBefore is when a field or schedule must have been deleted to be
+// past the trash retention.
+func trashedBefore() time.Time {
+	retention := time.Duration(config.Current().Trash.RetentionDays) * 24 * time.Hour
+	return time.Now().Add(-retention)
+}
+
+// Restore brings a trashed field back with the schedules deleted together
+// with it. It fails when another field took its code in the meantime, and
+// once the field is past the retention: gc-storage may have deleted its
+// images by then.
+func (f *FieldService) Restore(ctx context.Context, uuid string) (*dto.FieldResponse, error) {
+	trashed, err := f.repository.GetField().FindTrashedByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: trashed.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt := util.DeletedAt(trashed.DeletedAt)
+	if deletedAt != nil && deletedAt.Before(trashedBefore()) {
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldTrashExpired)
+	}
+
+	field, err := f.repository.GetField().Restore(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.FieldResponse{
+		UUID:              field.UUID,
+		VenueID:           field.VenueID,
+		Code:              field.Code,
+		Name:              field.Name,
+		PricePerHour:      field.PriceOn(time.Now()),
+		Status:            field.Status.GetStatusString(),
+		MaintenanceReason: field.MaintenanceReason,
+		ReopenAt:          formatDate(field.ReopenAt),
+		Images:            toImageResponses(field.Images),
+		CreatedAt:         field.CreatedAt,
+		UpdatedAt:         field.UpdatedAt,
+	}
+
+	return &response, nil
+}
+
+// PurgeTrash permanently deletes the fields and schedules that have been in
+// the trash longer than the configured retention, only those of the user's
+// venue for venue scoped grants. gc-storage removes the images left behind.
+func (f *FieldService) PurgeTrash(ctx context.Context) (*dto.PurgeTrashResponse, error) {
+	venueID, err := policy.VenueFilter(ctx, constants.FieldWrite)
+	if err != nil {
+		return nil, err
+	}
+	deletedBefore := trashedBefore()
+
+	schedules, err := f.repository.GetFieldSchedule().Purge(ctx, deletedBefore, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := f.repository.GetField().Purge(ctx, deletedBefore, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PurgeTrashResponse{Fields: fields, Schedules: schedules}, nil
+}
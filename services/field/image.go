@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/imaging"
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/policy"
+	"github.com/thomzes/field-service-booking-app/common/storage"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errConstant "github.com/thomzes/field-service-booking-app/constants/error"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
+	"github.com/thomzes/field-service-booking-app/domain/dto"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentUploads bounds how many images of one request are processed
+// and uploaded at the same time.
+const maxConcurrentUploads = 4
+
+const (
+	// maxDirectUploadSize applies to images uploaded straight to storage,
+	// which do not pass through the API's multipart limits.
+	maxDirectUploadSize = 20 * 1024 * 1024
+	uploadURLExpiry     = 15 * time.Minute
+)
+
+// directUploadContentTypes are the content types an upload URL is signed for.
+var directUploadContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+func (f *FieldService) validateUpload(images []multipart.FileHeader) error {
+	if images == nil || len(images) == 0 {
+		return errConstant.ErrInvalidUploadFile
+	}
+
+	for _, image := range images {
+		if image.Size > 5*1024*1024 {
+			return errConstant.ErrSizeTooBig
+		}
+	}
+
+	return nil
+}
+
+func (f *FieldService) processAndUploadImage(ctx context.Context, image multipart.FileHeader) (*models.FieldImage, error) {
+	file, err := image.Open()
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrInvalidUploadFile)
+	}
+	defer file.Close()
+
+	buffer := new(bytes.Buffer)
+	_, err = io.Copy(buffer, file)
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrInvalidUploadFile)
+	}
+
+	return f.processAndStoreImage(ctx, buffer.Bytes())
+}
+
+// processAndStoreImage generates the variants of an uploaded image and
+// uploads them. On failure the variants uploaded so far are deleted.
+func (f *FieldService) processAndStoreImage(ctx context.Context, data []byte) (*models.FieldImage, error) {
+	processed, err := imaging.Process(data, imaging.DefaultSizes)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedImage) || errors.Is(err, imaging.ErrImageTooLarge) {
+			return nil, errWrap.WrapError(ctx, errConstant.ErrUnsupportedImage)
+		}
+		return nil, errWrap.WrapError(ctx, err)
+	}
+
+	fieldImage := &models.FieldImage{
+		Hash:     processed.Hash,
+		Width:    processed.Width,
+		Height:   processed.Height,
+		BlurHash: processed.BlurHash,
+		Sizes:    make(models.ImageSizes, len(imaging.DefaultSizes)),
+	}
+	for _, variant := range processed.Variants {
+		key := imageKey(processed.Hash, variant)
+		url, err := f.storage.Upload(ctx, key, variant.Data, variant.ContentType)
+		if err != nil {
+			f.rollbackUploads(ctx, []models.FieldImage{*fieldImage})
+			return nil, errWrap.WrapError(ctx, err)
+		}
+
+		size := fieldImage.Sizes[variant.Size]
+		if variant.Format == imaging.FormatWebP {
+			size.WebPURL = url
+		} else {
+			size.URL = url
+		}
+		size.Width = variant.Width
+		size.Height = variant.Height
+		fieldImage.Sizes[variant.Size] = size
+	}
+
+	return fieldImage, nil
+}
+
+// imageKey addresses variants by the hash of the uploaded bytes, so the same
+// image uploaded twice reuses its objects.
+func imageKey(hash string, variant imaging.Variant) string {
+	return imagePrefix(hash) + fmt.Sprintf("%s.%s", variant.Size, variant.Extension())
+}
+
+// imagePrefix holds every variant stored for hash.
+func imagePrefix(hash string) string {
+	return fmt.Sprintf("images/%s/%s/", hash[:2], hash)
+}
+
+// rollbackUploads deletes the objects a failed request wrote for images,
+// unless an image in the database uses the same hash. Images that never got
+// uploaded are skipped. Like deleteImageObjects it outlives the request and
+// only logs failures.
+func (f *FieldService) rollbackUploads(ctx context.Context, images []models.FieldImage) {
+	ctx = context.WithoutCancel(ctx)
+	log := logger.FromContext(ctx)
+	for _, image := range images {
+		if image.Hash == "" {
+			continue
+		}
+		total, err := f.repository.GetFieldImage().CountByHash(ctx, image.Hash)
+		if err != nil || total > 0 {
+			continue
+		}
+
+		for _, url := range image.URLs() {
+			key, ok := f.storage.Key(url)
+			if !ok {
+				continue
+			}
+			err = f.storage.Delete(ctx, key)
+			if err != nil {
+				log.Errorf("failed to roll back image object %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// deleteImageObjects removes the stored variants of images the repository
+// reported as no longer used. It keeps going after the request is cancelled.
+// Failures are only logged: gc-storage collects what is left.
+func (f *FieldService) deleteImageObjects(ctx context.Context, images []models.FieldImage) {
+	ctx = context.WithoutCancel(ctx)
+	log := logger.FromContext(ctx)
+	for _, image := range images {
+		var keys []string
+		if image.Hash != "" {
+			err := f.storage.List(ctx, imagePrefix(image.Hash), func(object storage.Object) error {
+				keys = append(keys, object.Key)
+				return nil
+			})
+			if err != nil {
+				log.Errorf("failed to list image objects of %s: %v", image.Hash, err)
+				continue
+			}
+		} else {
+			for _, url := range image.URLs() {
+				key, ok := f.storage.Key(url)
+				if ok {
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		for _, key := range keys {
+			err := f.storage.Delete(ctx, key)
+			if err != nil {
+				log.Errorf("failed to delete image object %s: %v", key, err)
+			}
+		}
+	}
+}
+
+// uploadImage processes and uploads images concurrently, keeping them in
+// request order with the first one as the cover. When any image fails the
+// others are cancelled and whatever was already uploaded is rolled back.
+func (f *FieldService) uploadImage(ctx context.Context, images []multipart.FileHeader) ([]models.FieldImage, error) {
+	err := f.validateUpload(images)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldImages := make([]models.FieldImage, len(images))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentUploads)
+	for i, image := range images {
+		group.Go(func() error {
+			fieldImage, err := f.processAndUploadImage(groupCtx, image)
+			if err != nil {
+				return err
+			}
+			fieldImage.UUID = uuid.New()
+			fieldImage.Position = i
+			fieldImage.IsCover = i == 0
+			fieldImages[i] = *fieldImage
+			return nil
+		})
+	}
+
+	err = group.Wait()
+	if err != nil {
+		f.rollbackUploads(ctx, fieldImages)
+		return nil, err
+	}
+
+	return fieldImages, nil
+}
+
+func toImageResponse(image models.FieldImage) dto.FieldImageResponse {
+	sizes := make(map[string]dto.ImageSizeResponse, len(image.Sizes))
+	for name, size := range image.Sizes {
+		sizes[name] = dto.ImageSizeResponse{
+			URL:     size.URL,
+			WebPURL: size.WebPURL,
+			Width:   size.Width,
+			Height:  size.Height,
+		}
+	}
+	return dto.FieldImageResponse{
+		UUID:     image.UUID,
+		AltText:  image.AltText,
+		IsCover:  image.IsCover,
+		Width:    image.Width,
+		Height:   image.Height,
+		BlurHash: image.BlurHash,
+		Sizes:    sizes,
+	}
+}
+
+func toImageResponses(images []models.FieldImage) []dto.FieldImageResponse {
+	responses := make([]dto.FieldImageResponse, 0, len(images))
+	for _, image := range images {
+		responses = append(responses, toImageResponse(image))
+	}
+	return responses
+}
+
+func (f *FieldService) AddImage(ctx context.Context, fieldUUID string, req *dto.FieldImageRequest) (*dto.FieldImageResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.validateUpload([]multipart.FileHeader{*req.Image})
+	if err != nil {
+		return nil, err
+	}
+
+	fieldImage, err := f.processAndUploadImage(ctx, *req.Image)
+	if err != nil {
+		return nil, err
+	}
+	fieldImage.FieldID = field.ID
+	fieldImage.AltText = req.AltText
+
+	image, err := f.repository.GetFieldImage().Create(ctx, fieldImage)
+	if err != nil {
+		f.rollbackUploads(ctx, []models.FieldImage{*fieldImage})
+		return nil, err
+	}
+
+	response := toImageResponse(*image)
+	return &response, nil
+}
+
+func (f *FieldService) UpdateImage(ctx context.Context, fieldUUID, imageUUID string, req *dto.UpdateFieldImageRequest) (*dto.FieldImageResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := f.repository.GetFieldImage().FindByUUID(ctx, field.ID, imageUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.repository.GetFieldImage().UpdateAltText(ctx, image, req.AltText)
+	if err != nil {
+		return nil, err
+	}
+
+	image.AltText = req.AltText
+	response := toImageResponse(*image)
+	return &response, nil
+}
+
+func (f *FieldService) DeleteImage(ctx context.Context, fieldUUID, imageUUID string) error {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return err
+	}
+
+	image, err := f.repository.GetFieldImage().FindByUUID(ctx, field.ID, imageUUID)
+	if err != nil {
+		return err
+	}
+
+	orphaned, err := f.repository.GetFieldImage().Delete(ctx, image)
+	if err != nil {
+		return err
+	}
+
+	if orphaned {
+		f.deleteImageObjects(ctx, []models.FieldImage{*image})
+	}
+	return nil
+}
+
+func (f *FieldService) ReorderImages(ctx context.Context, fieldUUID string, req *dto.ReorderFieldImagesRequest) ([]dto.FieldImageResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.ImageUUIDs) != len(field.Images) {
+		return nil, errWrap.WrapError(ctx, errField.ErrInvalidImageOrder)
+	}
+
+	imageIDs := make(map[string]uint, len(field.Images))
+	for _, image := range field.Images {
+		imageIDs[image.UUID.String()] = image.ID
+	}
+
+	ordered := make([]uint, 0, len(req.ImageUUIDs))
+	for _, imageUUID := range req.ImageUUIDs {
+		imageID, ok := imageIDs[imageUUID]
+		if !ok {
+			return nil, errWrap.WrapError(ctx, errField.ErrInvalidImageOrder)
+		}
+		delete(imageIDs, imageUUID)
+		ordered = append(ordered, imageID)
+	}
+
+	err = f.repository.GetFieldImage().Reorder(ctx, field.ID, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := f.repository.GetFieldImage().FindAllByFieldID(ctx, field.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toImageResponses(images), nil
+}
+
+func (f *FieldService) SetCoverImage(ctx context.Context, fieldUUID, imageUUID string) ([]dto.FieldImageResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := f.repository.GetFieldImage().FindByUUID(ctx, field.ID, imageUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.repository.GetFieldImage().SetCover(ctx, field.ID, image.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := f.repository.GetFieldImage().FindAllByFieldID(ctx, field.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toImageResponses(images), nil
+}
+
+// uploadKey is where an image uploaded straight to storage waits for its
+// confirmation. Unconfirmed uploads are left for gc-storage.
+func uploadKey(fieldUUID uuid.UUID, uploadID string) string {
+	return fmt.Sprintf("uploads/%s/%s", fieldUUID, uploadID)
+}
+
+func (f *FieldService) CreateImageUploadURL(ctx context.Context, fieldUUID string, req *dto.FieldImageUploadURLRequest) (*dto.FieldImageUploadURLResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Size > maxDirectUploadSize {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSizeTooBig)
+	}
+
+	uploadID := uuid.New()
+	upload, err := f.storage.SignedUpload(ctx, uploadKey(field.UUID, uploadID.String()), storage.UploadPolicy{
+		ContentType: req.ContentType,
+		MaxSize:     req.Size,
+		Expiry:      uploadURLExpiry,
+	})
+	if err != nil {
+		return nil, errWrap.WrapError(ctx, err)
+	}
+
+	return &dto.FieldImageUploadURLResponse{
+		UploadID:  uploadID,
+		URL:       upload.URL,
+		Method:    upload.Method,
+		Headers:   upload.Headers,
+		Fields:    upload.Fields,
+		ExpiresAt: time.Now().Add(uploadURLExpiry),
+	}, nil
+}
+
+// ConfirmImageUpload turns an image uploaded to a signed URL into a field
+// image once its stored size and content type are within what upload URLs are
+// signed for. The upload itself is deleted whether or not it was a valid image.
+func (f *FieldService) ConfirmImageUpload(ctx context.Context, fieldUUID string, req *dto.ConfirmFieldImageUploadRequest) (*dto.FieldImageResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, fieldUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	key := uploadKey(field.UUID, req.UploadID)
+	object, err := f.storage.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, errWrap.WrapError(ctx, errField.ErrUploadNotFound)
+		}
+		return nil, errWrap.WrapError(ctx, err)
+	}
+	defer f.deleteUpload(ctx, key)
+
+	if object.Size > maxDirectUploadSize {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrSizeTooBig)
+	}
+	if !slices.Contains(directUploadContentTypes, object.ContentType) {
+		return nil, errWrap.WrapError(ctx, errConstant.ErrInvalidUploadFile)
+	}
+
+	data, err := f.storage.Download(ctx, key, maxDirectUploadSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrObjectNotFound):
+			return nil, errWrap.WrapError(ctx, errField.ErrUploadNotFound)
+		case errors.Is(err, storage.ErrObjectTooLarge):
+			return nil, errWrap.WrapError(ctx, errConstant.ErrSizeTooBig)
+		}
+		return nil, errWrap.WrapError(ctx, err)
+	}
+
+	fieldImage, err := f.processAndStoreImage(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	fieldImage.FieldID = field.ID
+	fieldImage.AltText = req.AltText
+
+	image, err := f.repository.GetFieldImage().Create(ctx, fieldImage)
+	if err != nil {
+		f.rollbackUploads(ctx, []models.FieldImage{*fieldImage})
+		return nil, err
+	}
+
+	response := toImageResponse(*image)
+	return &response, nil
+}
+
+func (f *FieldService) deleteUpload(ctx context.Context, key string) {
+	err := f.storage.Delete(context.WithoutCancel(ctx), key)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to delete upload %s: %v", key, err)
+	}
+}
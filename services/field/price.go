@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/policy"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
+	"github.com/thomzes/field-service-booking-app/domain/dto"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+)
+
+// today returns the current date at midnight UTC, the way date columns are
+// read back.
+func today() time.Time {
+	date, _ := time.Parse(time.DateOnly, time.Now().Format(time.DateOnly))
+	return date
+}
+
+// GetPrices lists the price history of a field, or only the price effective
+// on param.Date.
+func (f *FieldService) GetPrices(ctx context.Context, uuid string, param *dto.FieldPriceRequestParam) ([]dto.FieldPriceResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := field.Prices
+	if param.Date != "" {
+		date, _ := time.Parse(time.DateOnly, param.Date)
+		prices = nil
+		if price := field.PriceAt(date); price != nil {
+			prices = []models.FieldPrice{*price}
+		}
+	}
+
+	return toPriceResponses(prices), nil
+}
+
+// SchedulePrice sets the price of a field from the given day on. A change
+// already scheduled for that day is replaced.
+func (f *FieldService) SchedulePrice(ctx context.Context, uuid string, request *dto.FieldPriceRequest) (*dto.FieldPriceResponse, error) {
+	field, err := f.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveFrom, _ := time.Parse(time.DateOnly, request.EffectiveFrom)
+	if effectiveFrom.Before(today()) {
+		return nil, errWrap.WrapError(ctx, errField.ErrPriceInPast)
+	}
+
+	price, err := f.repository.GetFieldPrice().Set(ctx, field.ID, request.PricePerHour, effectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPriceResponse(*price)
+	return &response, nil
+}
+
+// CancelPrice removes a price change that has not taken effect yet.
+func (f *FieldService) CancelPrice(ctx context.Context, uuid, priceUUID string) error {
+	field, err := f.repository.GetField().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return err
+	}
+
+	price, err := f.repository.GetFieldPrice().FindByUUID(ctx, field.ID, priceUUID)
+	if err != nil {
+		return err
+	}
+
+	if !price.EffectiveFrom.After(today()) {
+		return errWrap.WrapError(ctx, errField.ErrPriceNotScheduled)
+	}
+
+	return f.repository.GetFieldPrice().Delete(ctx, price)
+}
+
+func toPriceResponse(price models.FieldPrice) dto.FieldPriceResponse {
+	return dto.FieldPriceResponse{
+		UUID:          price.UUID,
+		PricePerHour:  price.PricePerHour,
+		EffectiveFrom: price.EffectiveFrom.Format(time.DateOnly),
+		EffectiveTo:   formatDate(price.EffectiveTo),
+		CreatedAt:     price.CreatedAt,
+		UpdatedAt:     price.UpdatedAt,
+	}
+}
+
+func toPriceResponses(prices []models.FieldPrice) []dto.FieldPriceResponse {
+	responses := make([]dto.FieldPriceResponse, 0, len(prices))
+	for _, price := range prices {
+		responses = append(responses, toPriceResponse(price))
+	}
+	return responses
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/policy"
+	"github.com/thomzes/field-service-booking-app/constants"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
+	"github.com/thomzes/field-service-booking-app/domain/dto"
+	"github.com/thomzes/field-service-booking-app/domain/models"
+	"github.com/thomzes/field-service-booking-app/repositories"
+)
+
+// statusTransitions lists the statuses a field can move to from each status.
+// Moving from Maintenance to Maintenance changes the reason or reopen date.
+var statusTransitions = map[constants.FieldStatus][]constants.FieldStatus{
+	constants.Draft:       {constants.Active, constants.Archived},
+	constants.Active:      {constants.Maintenance, constants.Archived},
+	constants.Maintenance: {constants.Active, constants.Maintenance, constants.Archived},
+	constants.Archived:    {constants.Draft, constants.Active},
+}
+
+// canTransition reports whether a field may move from one status to another.
+func canTransition(from, to constants.FieldStatus) bool {
+	return slices.Contains(statusTransitions[from], to)
+}
+
+// publicStatuses are the statuses of the fields users without field:write see.
+var publicStatuses = []constants.FieldStatus{constants.Active}
+
+// visibleStatuses returns the statuses the user in ctx may see, nil for all of
+// them. Anonymous callers only see public fields.
+func visibleStatuses(ctx context.Context) []constants.FieldStatus {
+	user := policy.UserFromContext(ctx)
+	if user != nil && policy.GrantFor(user.Role, constants.FieldWrite) != policy.GrantNone {
+		return nil
+	}
+	return publicStatuses
+}
+
+func isVisible(ctx context.Context, status constants.FieldStatus) bool {
+	statuses := visibleStatuses(ctx)
+	return statuses == nil || slices.Contains(statuses, status)
+}
+
+// createStatus returns the status of a new field, Draft unless asked for.
+func createStatus(status string) constants.FieldStatus {
+	if status == "" {
+		return constants.Draft
+	}
+	return constants.FieldStatusName(status).GetStatusInt()
+}
+
+// UpdateStatus moves a field through its lifecycle. Maintenance blocks the
+// available schedules from today until the reopen date, archiving blocks all
+// future ones, and activating makes them available again. Bookings are left
+// as they are and counted in the response. The status and the schedules change
+// in one transaction.
+func (f *FieldService) UpdateStatus(ctx context.Context, uuid string, request *dto.UpdateFieldStatusRequest) (*dto.FieldStatusResponse, error) {
+	var (
+		field    *models.Field
+		reason   string
+		reopenAt *time.Time
+		response = &dto.FieldStatusResponse{}
+	)
+	status := constants.FieldStatusName(request.Status).GetStatusInt()
+
+	err := f.repository.Transaction(ctx, func(repository repositories.IRepositoryRegistry) error {
+		var err error
+		field, err = repository.GetField().FindByUUID(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		err = policy.Authorize(ctx, constants.FieldWrite, policy.Resource{VenueID: field.VenueID})
+		if err != nil {
+			return err
+		}
+
+		if !canTransition(field.Status, status) {
+			return errWrap.WrapError(ctx, errField.ErrInvalidFieldStatus)
+		}
+
+		if status == constants.Maintenance {
+			reason = request.Reason
+			if request.ReopenAt != "" {
+				date, _ := time.Parse(time.DateOnly, request.ReopenAt)
+				if !date.After(today()) {
+					return errWrap.WrapError(ctx, errField.ErrInvalidReopenDate)
+				}
+				reopenAt = &date
+			}
+		}
+
+		err = repository.GetField().UpdateStatus(ctx, uuid, &models.Field{
+			Status:            status,
+			MaintenanceReason: reason,
+			ReopenAt:          reopenAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		schedules := repository.GetFieldSchedule()
+		switch status {
+		case constants.Maintenance, constants.Archived:
+			if field.Status == constants.Maintenance {
+				_, err = schedules.Unblock(ctx, field.ID, today())
+				if err != nil {
+					return err
+				}
+			}
+
+			response.BlockedSchedules, err = schedules.Block(ctx, field.ID, today(), reopenAt)
+			if err != nil {
+				return err
+			}
+
+			response.BookedSchedules, err = schedules.CountBooked(ctx, field.ID, today(), reopenAt)
+			if err != nil {
+				return err
+			}
+		case constants.Active:
+			response.UnblockedSchedules, err = schedules.Unblock(ctx, field.ID, today())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response.Field = &dto.FieldResponse{
+		UUID:              field.UUID,
+		VenueID:           field.VenueID,
+		Code:              field.Code,
+		Name:              field.Name,
+		PricePerHour:      field.PriceOn(time.Now()),
+		Status:            status.GetStatusString(),
+		MaintenanceReason: reason,
+		ReopenAt:          formatDate(reopenAt),
+		Images:            toImageResponses(field.Images),
+		CreatedAt:         field.CreatedAt,
+		UpdatedAt:         field.UpdatedAt,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	clients "github.com/thomzes/field-service-booking-app/clients/user"
+	"github.com/thomzes/field-service-booking-app/config"
+	"github.com/thomzes/field-service-booking-app/constants"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from    constants.FieldStatus
+		to      constants.FieldStatus
+		allowed bool
+	}{
+		{from: constants.Draft, to: constants.Draft},
+		{from: constants.Draft, to: constants.Active, allowed: true},
+		{from: constants.Draft, to: constants.Maintenance},
+		{from: constants.Draft, to: constants.Archived, allowed: true},
+		{from: constants.Active, to: constants.Draft},
+		{from: constants.Active, to: constants.Active},
+		{from: constants.Active, to: constants.Maintenance, allowed: true},
+		{from: constants.Active, to: constants.Archived, allowed: true},
+		{from: constants.Maintenance, to: constants.Draft},
+		{from: constants.Maintenance, to: constants.Active, allowed: true},
+		{from: constants.Maintenance, to: constants.Maintenance, allowed: true},
+		{from: constants.Maintenance, to: constants.Archived, allowed: true},
+		{from: constants.Archived, to: constants.Draft, allowed: true},
+		{from: constants.Archived, to: constants.Active, allowed: true},
+		{from: constants.Archived, to: constants.Maintenance},
+		{from: constants.Archived, to: constants.Archived},
+	}
+
+	for _, test := range tests {
+		name := string(test.from.GetStatusString()) + " to " + string(test.to.GetStatusString())
+		t.Run(name, func(t *testing.T) {
+			allowed := canTransition(test.from, test.to)
+			if allowed != test.allowed {
+				t.Fatalf("canTransition(%s) = %t, want %t", name, allowed, test.allowed)
+			}
+		})
+	}
+}
+
+func TestIsVisible(t *testing.T) {
+	config.Set(&config.AppConfig{})
+
+	tests := []struct {
+		name    string
+		user    *clients.UserData
+		status  constants.FieldStatus
+		visible bool
+	}{
+		{name: "anonymous active", status: constants.Active, visible: true},
+		{name: "anonymous maintenance", status: constants.Maintenance},
+		{name: "customer active", user: &clients.UserData{Role: constants.Customer}, status: constants.Active, visible: true},
+		{name: "customer maintenance", user: &clients.UserData{Role: constants.Customer}, status: constants.Maintenance},
+		{name: "admin maintenance", user: &clients.UserData{Role: constants.Admin}, status: constants.Maintenance, visible: true},
+		{name: "anonymous draft", status: constants.Draft},
+		{name: "anonymous archived", status: constants.Archived},
+		{name: "customer archived", user: &clients.UserData{Role: constants.Customer}, status: constants.Archived},
+		{name: "admin draft", user: &clients.UserData{Role: constants.Admin}, status: constants.Draft, visible: true},
+		{name: "admin archived", user: &clients.UserData{Role: constants.Admin}, status: constants.Archived, visible: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.user != nil {
+				ctx = context.WithValue(ctx, constants.User, test.user)
+			}
+
+			visible := isVisible(ctx, test.status)
+			if visible != test.visible {
+				t.Fatalf("isVisible() = %t, want %t", visible, test.visible)
+			}
+		})
+	}
+}
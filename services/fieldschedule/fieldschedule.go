@@ -2,12 +2,17 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	errWrap "github.com/thomzes/field-service-booking-app/common/error"
+	"github.com/thomzes/field-service-booking-app/common/metrics"
+	"github.com/thomzes/field-service-booking-app/common/policy"
 	"github.com/thomzes/field-service-booking-app/common/util"
 	"github.com/thomzes/field-service-booking-app/constants"
+	errField "github.com/thomzes/field-service-booking-app/constants/error/field"
 	errFieldSchedule "github.com/thomzes/field-service-booking-app/constants/error/fieldschedule"
 	"github.com/thomzes/field-service-booking-app/domain/dto"
 	"github.com/thomzes/field-service-booking-app/domain/models"
@@ -27,6 +32,8 @@ type IFieldScheduleService interface {
 	Update(context.Context, string, *dto.UpdateFieldScheduleRequest) (*dto.FieldScheduleResponse, error)
 	UpdateStatus(context.Context, *dto.UpdateStatusFieldScheduleRequest) error
 	Delete(context.Context, string) error
+	GetTrash(context.Context, *dto.TrashRequestParam) (*util.PaginationResult, error)
+	Restore(context.Context, string) (*dto.FieldScheduleResponse, error)
 }
 
 func NewFieldScheduleService(repository repositories.IRepositoryRegistry) IFieldScheduleService {
@@ -44,7 +51,7 @@ func (f *FieldScheduleService) GetAllWithPagination(ctx context.Context, param *
 		fieldScheduleResults = append(fieldScheduleResults, dto.FieldScheduleResponse{
 			UUID:         fieldSchedule.UUID,
 			FieldName:    fieldSchedule.Field.Name,
-			PricePerHour: fieldSchedule.Field.PricePerHour,
+			PricePerHour: pricePerHour(fieldSchedule),
 			Date:         fieldSchedule.Date.Format("2006-01-02"),
 			Status:       fieldSchedule.Status.GetStatusString(),
 			Time:         fmt.Sprintf("%s-%s", fieldSchedule.Time.StartTime, fieldSchedule.Time.EndTime),
@@ -65,6 +72,30 @@ func (f *FieldScheduleService) GetAllWithPagination(ctx context.Context, param *
 	return &response, err
 }
 
+// pricePerHour returns the price a booked schedule was booked at, or the
+// price effective on its date while the schedule is available.
+func pricePerHour(fieldSchedule models.FieldSchedule) int {
+	if fieldSchedule.PricePerHour != nil {
+		return *fieldSchedule.PricePerHour
+	}
+	return fieldSchedule.Field.PriceOn(fieldSchedule.Date)
+}
+
+// slotStatus returns the status of a new schedule and why it is blocked:
+// blocked while the field is archived or closed for maintenance on date,
+// otherwise available.
+func slotStatus(field *models.Field, date time.Time) (constants.FieldScheduleStatus, string) {
+	switch field.Status {
+	case constants.Archived:
+		return constants.Blocked, constants.BlockReasonFieldStatus
+	case constants.Maintenance:
+		if field.ReopenAt == nil || date.Format(time.DateOnly) < field.ReopenAt.Format(time.DateOnly) {
+			return constants.Blocked, constants.BlockReasonFieldStatus
+		}
+	}
+	return constants.Available, ""
+}
+
 func (f *FieldScheduleService) convertMonthName(inputString string) string {
 	date, err := time.Parse(time.DateOnly, inputString)
 	if err != nil {
@@ -98,7 +129,10 @@ func (f *FieldScheduleService) GetAllByFieldIDAndDate(ctx context.Context, uuid,
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(field)
+
+	if field.Status != constants.Active {
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldUnavailable)
+	}
 
 	fieldSchedules, err := f.repository.GetFieldSchedule().FindAllByFieldIDAndDate(ctx, int(field.ID), date)
 	if err != nil {
@@ -107,7 +141,7 @@ func (f *FieldScheduleService) GetAllByFieldIDAndDate(ctx context.Context, uuid,
 
 	fieldScheduleResults := make([]dto.FieldScheduleForBookingResponse, 0, len(fieldSchedules))
 	for _, fieldSchedule := range fieldSchedules {
-		priceHour := float64(fieldSchedule.Field.PricePerHour)
+		priceHour := float64(pricePerHour(fieldSchedule))
 		startTime, _ := time.Parse("15:04:05", fieldSchedule.Time.StartTime)
 		endTime, _ := time.Parse("15:04:05", fieldSchedule.Time.EndTime)
 		fieldScheduleResults = append(fieldScheduleResults, dto.FieldScheduleForBookingResponse{
@@ -131,7 +165,7 @@ func (f *FieldScheduleService) GetByUUID(ctx context.Context, uuid string) (*dto
 	response := dto.FieldScheduleResponse{
 		UUID:         fieldSchedule.UUID,
 		FieldName:    fieldSchedule.Field.Name,
-		PricePerHour: fieldSchedule.Field.PricePerHour,
+		PricePerHour: pricePerHour(*fieldSchedule),
 		Date:         fieldSchedule.Date.Format(time.DateOnly),
 		Status:       fieldSchedule.Status.GetStatusString(),
 		Time:         fmt.Sprintf("%s-%s", fieldSchedule.Time.StartTime, fieldSchedule.Time.EndTime),
@@ -148,6 +182,11 @@ func (f *FieldScheduleService) Create(ctx context.Context, request *dto.FieldSch
 		return err
 	}
 
+	err = policy.Authorize(ctx, constants.ScheduleWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return err
+	}
+
 	fieldSchedules := make([]models.FieldSchedule, 0, len(request.TimeIDs))
 	dateParsed, _ := time.Parse(time.DateOnly, request.Date)
 	for _, timeID := range request.TimeIDs {
@@ -161,14 +200,17 @@ func (f *FieldScheduleService) Create(ctx context.Context, request *dto.FieldSch
 			return err
 		}
 		if schedule != nil {
+			metrics.BookingConflicts.WithLabelValues("create").Inc()
 			return errFieldSchedule.ErrFieldScheduleIsExist
 		}
+		status, blockReason := slotStatus(field, dateParsed)
 		fieldSchedules = append(fieldSchedules, models.FieldSchedule{
-			UUID:    uuid.New(),
-			FieldID: field.ID,
-			TimeID:  scheduleTime.ID,
-			Date:    dateParsed,
-			Status:  constants.Available,
+			UUID:        uuid.New(),
+			FieldID:     field.ID,
+			TimeID:      scheduleTime.ID,
+			Date:        dateParsed,
+			Status:      status,
+			BlockReason: blockReason,
 		})
 	}
 
@@ -176,6 +218,7 @@ func (f *FieldScheduleService) Create(ctx context.Context, request *dto.FieldSch
 	if err != nil {
 		return err
 	}
+	metrics.SlotsGenerated.Add(float64(len(fieldSchedules)))
 
 	return nil
 }
@@ -187,6 +230,11 @@ func (f *FieldScheduleService) GenerateScheduleForOneMonth(ctx context.Context,
 		return err
 	}
 
+	err = policy.Authorize(ctx, constants.ScheduleWrite, policy.Resource{VenueID: field.VenueID})
+	if err != nil {
+		return err
+	}
+
 	times, err := f.repository.GetTime().FindAll(ctx)
 	if err != nil {
 		return err
@@ -205,15 +253,18 @@ func (f *FieldScheduleService) GenerateScheduleForOneMonth(ctx context.Context,
 			}
 
 			if schedule != nil {
+				metrics.BookingConflicts.WithLabelValues("generate").Inc()
 				return errFieldSchedule.ErrFieldScheduleIsExist
 			}
 
+			status, blockReason := slotStatus(field, currentDate)
 			fieldSchedules = append(fieldSchedules, models.FieldSchedule{
-				UUID:    uuid.New(),
-				FieldID: field.ID,
-				TimeID:  item.ID,
-				Date:    currentDate,
-				Status:  constants.Available,
+				UUID:        uuid.New(),
+				FieldID:     field.ID,
+				TimeID:      item.ID,
+				Date:        currentDate,
+				Status:      status,
+				BlockReason: blockReason,
 			})
 		}
 	}
@@ -222,6 +273,7 @@ func (f *FieldScheduleService) GenerateScheduleForOneMonth(ctx context.Context,
 	if err != nil {
 		return err
 	}
+	metrics.SlotsGenerated.Add(float64(len(fieldSchedules)))
 
 	return nil
 }
@@ -232,6 +284,11 @@ func (f *FieldScheduleService) Update(ctx context.Context, uuid string, request
 		return nil, err
 	}
 
+	err = policy.Authorize(ctx, constants.ScheduleWrite, policy.Resource{VenueID: fieldSchedule.Field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
 	scheduleTime, err := f.repository.GetTime().FindByUUID(ctx, request.TimeID)
 	if err != nil {
 		return nil, err
@@ -250,6 +307,7 @@ func (f *FieldScheduleService) Update(ctx context.Context, uuid string, request
 		}
 
 		if checkDate != nil {
+			metrics.BookingConflicts.WithLabelValues("update").Inc()
 			return nil, errFieldSchedule.ErrFieldScheduleIsExist
 		}
 	}
@@ -267,7 +325,7 @@ func (f *FieldScheduleService) Update(ctx context.Context, uuid string, request
 		UUID:         fieldResult.UUID,
 		FieldName:    fieldResult.Field.Name,
 		Date:         fieldResult.Date.Format(time.DateOnly),
-		PricePerHour: fieldResult.Field.PricePerHour,
+		PricePerHour: pricePerHour(*fieldResult),
 		Status:       fieldResult.Status.GetStatusString(),
 		Time:         fmt.Sprintf("%s - %s", scheduleTime.StartTime, scheduleTime.EndTime),
 		CreatedAt:    fieldResult.CreatedAt,
@@ -277,24 +335,49 @@ func (f *FieldScheduleService) Update(ctx context.Context, uuid string, request
 	return &response, nil
 }
 
+// UpdateStatus books every requested schedule or none of them.
 func (f *FieldScheduleService) UpdateStatus(ctx context.Context, request *dto.UpdateStatusFieldScheduleRequest) error {
-	for _, item := range request.FieldScheduleIDs {
-		_, err := f.repository.GetFieldSchedule().FindByUUID(ctx, item)
-		if err != nil {
-			return err
-		}
+	err := f.repository.Transaction(ctx, func(repository repositories.IRepositoryRegistry) error {
+		for _, item := range request.FieldScheduleIDs {
+			fieldSchedule, err := repository.GetFieldSchedule().FindByUUID(ctx, item)
+			if err != nil {
+				return err
+			}
 
-		err = f.repository.GetFieldSchedule().UpdateStatus(ctx, constants.Booked, item)
-		if err != nil {
-			return err
+			if fieldSchedule.Status == constants.Blocked || fieldSchedule.Field.Status != constants.Active {
+				return errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleBlocked)
+			}
+
+			if fieldSchedule.Status == constants.Booked {
+				metrics.BookingConflicts.WithLabelValues("book").Inc()
+				return errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleBooked)
+			}
+
+			err = repository.GetFieldSchedule().Book(ctx, item, fieldSchedule.Field.PriceOn(fieldSchedule.Date))
+			if err != nil {
+				if errors.Is(err, errFieldSchedule.ErrFieldScheduleBooked) {
+					metrics.BookingConflicts.WithLabelValues("book").Inc()
+				}
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	metrics.SlotStatusChanges.WithLabelValues("booked").Add(float64(len(request.FieldScheduleIDs)))
 	return nil
 }
 
 func (f *FieldScheduleService) Delete(ctx context.Context, uuid string) error {
-	_, err := f.repository.GetFieldSchedule().FindByUUID(ctx, uuid)
+	fieldSchedule, err := f.repository.GetFieldSchedule().FindByUUID(ctx, uuid)
+	if err != nil {
+		return err
+	}
+
+	err = policy.Authorize(ctx, constants.ScheduleWrite, policy.Resource{VenueID: fieldSchedule.Field.VenueID})
 	if err != nil {
 		return err
 	}
@@ -306,3 +389,85 @@ func (f *FieldScheduleService) Delete(ctx context.Context, uuid string) error {
 
 	return nil
 }
+
+func (f *FieldScheduleService) GetTrash(ctx context.Context, param *dto.TrashRequestParam) (*util.PaginationResult, error) {
+	venueID, err := policy.VenueFilter(ctx, constants.ScheduleWrite)
+	if err != nil {
+		return nil, err
+	}
+	param.VenueID = venueID
+
+	fieldSchedules, total, err := f.repository.GetFieldSchedule().FindAllTrashed(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldScheduleResults := make([]dto.FieldScheduleResponse, 0, len(fieldSchedules))
+	for _, fieldSchedule := range fieldSchedules {
+		fieldScheduleResults = append(fieldScheduleResults, dto.FieldScheduleResponse{
+			UUID:         fieldSchedule.UUID,
+			FieldName:    fieldSchedule.Field.Name,
+			PricePerHour: pricePerHour(fieldSchedule),
+			Date:         fieldSchedule.Date.Format(time.DateOnly),
+			Status:       fieldSchedule.Status.GetStatusString(),
+			Time:         fmt.Sprintf("%s-%s", fieldSchedule.Time.StartTime, fieldSchedule.Time.EndTime),
+			CreatedAt:    fieldSchedule.CreatedAt,
+			UpdatedAt:    fieldSchedule.UpdatedAt,
+			DeletedAt:    util.DeletedAt(fieldSchedule.DeletedAt),
+		})
+	}
+
+	response := util.GeneratePagination(util.PaginationParam{
+		Count: total,
+		Page:  param.Page,
+		Limit: param.Limit,
+		Data:  fieldScheduleResults,
+	})
+
+	return &response, nil
+}
+
+// Restore brings a trashed schedule back. Its field has to be live and the
+// slot must not have been scheduled again.
+func (f *FieldScheduleService) Restore(ctx context.Context, uuid string) (*dto.FieldScheduleResponse, error) {
+	fieldSchedule, err := f.repository.GetFieldSchedule().FindTrashedByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Authorize(ctx, constants.ScheduleWrite, policy.Resource{VenueID: fieldSchedule.Field.VenueID})
+	if err != nil {
+		return nil, err
+	}
+
+	if util.DeletedAt(fieldSchedule.Field.DeletedAt) != nil {
+		return nil, errWrap.WrapError(ctx, errField.ErrFieldNotFound)
+	}
+
+	existing, err := f.repository.GetFieldSchedule().FindByDateAndTimeID(ctx, fieldSchedule.Date.Format(time.DateOnly),
+		int(fieldSchedule.TimeID), int(fieldSchedule.FieldID))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errWrap.WrapError(ctx, errFieldSchedule.ErrFieldScheduleIsExist)
+	}
+
+	err = f.repository.GetFieldSchedule().Restore(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.FieldScheduleResponse{
+		UUID:         fieldSchedule.UUID,
+		FieldName:    fieldSchedule.Field.Name,
+		PricePerHour: pricePerHour(*fieldSchedule),
+		Date:         fieldSchedule.Date.Format(time.DateOnly),
+		Status:       fieldSchedule.Status.GetStatusString(),
+		Time:         fmt.Sprintf("%s-%s", fieldSchedule.Time.StartTime, fieldSchedule.Time.EndTime),
+		CreatedAt:    fieldSchedule.CreatedAt,
+		UpdatedAt:    fieldSchedule.UpdatedAt,
+	}
+
+	return &response, nil
+}
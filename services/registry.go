@@ -1,7 +1,8 @@
 package services
 
 import (
-	"github.com/thomzes/field-service-booking-app/common/gcs"
+	"github.com/thomzes/field-service-booking-app/common/events"
+	"github.com/thomzes/field-service-booking-app/common/storage"
 	"github.com/thomzes/field-service-booking-app/repositories"
 	fieldService "github.com/thomzes/field-service-booking-app/services/field"
 	fieldScheduleService "github.com/thomzes/field-service-booking-app/services/fieldschedule"
@@ -10,7 +11,8 @@ import (
 
 type Registry struct {
 	repository repositories.IRepositoryRegistry
-	gcs        gcs.IGCSClient
+	storage    storage.IStorage
+	publisher  events.IPublisher
 }
 
 type IServiceRegistry interface {
@@ -19,12 +21,12 @@ type IServiceRegistry interface {
 	GetTime() timeService.ITimeService
 }
 
-func NewServiceRegistry(repository repositories.IRepositoryRegistry, gcs gcs.IGCSClient) IServiceRegistry {
-	return &Registry{repository: repository, gcs: gcs}
+func NewServiceRegistry(repository repositories.IRepositoryRegistry, storage storage.IStorage, publisher events.IPublisher) IServiceRegistry {
+	return &Registry{repository: repository, storage: storage, publisher: publisher}
 }
 
 func (r *Registry) GetField() fieldService.IFieldService {
-	return fieldService.NewFieldService(r.repository, r.gcs)
+	return fieldService.NewFieldService(r.repository, r.storage, r.publisher)
 }
 
 func (r *Registry) GetFieldSchedule() fieldScheduleService.IFieldScheduleService {
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thomzes/field-service-booking-app/common/logger"
+	"github.com/thomzes/field-service-booking-app/common/storage"
+	"github.com/thomzes/field-service-booking-app/repositories"
+)
+
+// DefaultPrefixes hold the field image variants and the direct uploads that
+// wait for confirmation.
+var DefaultPrefixes = []string{"images/", "uploads/"}
+
+type Options struct {
+	Prefixes []string
+	// GracePeriod keeps objects updated recently, such as uploads whose image
+	// is not saved yet.
+	GracePeriod time.Duration
+	// DeletedRetention keeps the images of soft-deleted fields so the fields
+	// can still be restored.
+	DeletedRetention time.Duration
+	DryRun           bool
+}
+
+type Summary struct {
+	Scanned      int
+	Referenced   int
+	Recent       int
+	Deleted      int
+	Failed       int
+	DeletedBytes int64
+	DryRun       bool
+}
+
+type StorageGCService struct {
+	repository repositories.IRepositoryRegistry
+	storage    storage.IStorage
+	options    Options
+}
+
+type IStorageGCService interface {
+	Run(context.Context) (*Summary, error)
+}
+
+func NewStorageGCService(repository repositories.IRepositoryRegistry, storage storage.IStorage, options Options) IStorageGCService {
+	if len(options.Prefixes) == 0 {
+		options.Prefixes = DefaultPrefixes
+	}
+	return &StorageGCService{repository: repository, storage: storage, options: options}
+}
+
+// Run deletes the objects no field image references. Objects are listed before
+// the references are loaded, and each object is stat'ed and looked up in the
+// field images again right before it is deleted, so an image saved or uploaded
+// again while the collector runs keeps its objects.
+func (s *StorageGCService) Run(ctx context.Context) (*Summary, error) {
+	now := time.Now()
+	cutoff := now.Add(-s.options.GracePeriod)
+	summary := &Summary{DryRun: s.options.DryRun}
+
+	var candidates []storage.Object
+	for _, prefix := range s.options.Prefixes {
+		err := s.storage.List(ctx, prefix, func(object storage.Object) error {
+			summary.Scanned++
+			if object.UpdatedAt.After(cutoff) {
+				summary.Recent++
+				return nil
+			}
+			candidates = append(candidates, object)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deletedAfter := now.Add(-s.options.DeletedRetention)
+	referenced, err := s.referencedKeys(ctx, deletedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.FromContext(ctx)
+	for _, object := range candidates {
+		if _, ok := referenced[object.Key]; ok {
+			summary.Referenced++
+			continue
+		}
+
+		if s.options.DryRun {
+			log.Infof("would delete %s (%d bytes)", object.Key, object.Size)
+			summary.Deleted++
+			summary.DeletedBytes += object.Size
+			continue
+		}
+
+		current, err := s.storage.Stat(ctx, object.Key)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			continue
+		}
+		if err == nil && current.UpdatedAt.After(cutoff) {
+			summary.Recent++
+			continue
+		}
+		if err == nil {
+			var stillReferenced bool
+			stillReferenced, err = s.repository.GetFieldImage().IsReferenced(ctx, object.Key, deletedAfter)
+			if err == nil && stillReferenced {
+				summary.Referenced++
+				continue
+			}
+		}
+		if err == nil {
+			err = s.storage.Delete(ctx, object.Key)
+		}
+		if err != nil {
+			log.Errorf("failed to delete %s: %v", object.Key, err)
+			summary.Failed++
+			continue
+		}
+
+		log.Infof("deleted %s (%d bytes)", object.Key, object.Size)
+		summary.Deleted++
+		summary.DeletedBytes += object.Size
+	}
+
+	return summary, nil
+}
+
+func (s *StorageGCService) referencedKeys(ctx context.Context, deletedAfter time.Time) (map[string]struct{}, error) {
+	images, err := s.repository.GetFieldImage().FindAllReferenced(ctx, deletedAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for _, image := range images {
+		for _, url := range image.URLs() {
+			key, ok := s.storage.Key(url)
+			if ok {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+	return keys, nil
+}
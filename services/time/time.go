@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 
+	"github.com/thomzes/field-service-booking-app/common/policy"
+	"github.com/thomzes/field-service-booking-app/constants"
 	"github.com/thomzes/field-service-booking-app/domain/dto"
 	"github.com/thomzes/field-service-booking-app/domain/models"
 	"github.com/thomzes/field-service-booking-app/repositories"
@@ -60,6 +62,12 @@ func (t *TimeService) GetByUUID(ctx context.Context, uuid string) (*dto.TimeResp
 }
 
 func (t *TimeService) Create(ctx context.Context, request *dto.TimeRequest) (*dto.TimeResponse, error) {
+	// time slots are shared by every venue, so venue scoped grants do not cover them
+	err := policy.Authorize(ctx, constants.TimeWrite, policy.Resource{})
+	if err != nil {
+		return nil, err
+	}
+
 	time := &dto.TimeRequest{
 		StartTime: request.StartTime,
 		EndTime:   request.EndTime,